@@ -0,0 +1,28 @@
+package errs
+
+// Codes identifying each kind of diagnostic. The first letter names the
+// phase that reports it: S for the scanner, P for the parser and R for the
+// resolver. Errors during execution use E, the letter they were first
+// given, as codes are part of the JSON diagnostics output and never change
+// once written out.
+const (
+	CodeUnexpectedCharacter    = "S001"
+	CodeUnterminatedString     = "S002"
+	CodeUnterminatedComment    = "S003"
+	CodeInvalidEscape          = "S004"
+	CodeMalformedNumber        = "S005"
+	CodeConfusableCharacter    = "S006"
+	CodeMixedScripts           = "S007"
+	CodeSyntax                 = "P001"
+	CodeInvalidAssignment      = "P002"
+	CodeTooManyArguments       = "P003"
+	CodeReadInInitializer      = "R001"
+	CodeDuplicateDeclaration   = "R002"
+	CodeTopLevelReturn         = "R003"
+	CodeReturnFromInitializer  = "R004"
+	CodeThisOutsideClass       = "R005"
+	CodeSuperOutsideClass      = "R006"
+	CodeSuperWithoutSuperclass = "R007"
+	CodeInheritFromSelf        = "R008"
+	CodeRuntime                = "E001"
+)
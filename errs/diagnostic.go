@@ -0,0 +1,87 @@
+package errs
+
+import "github.com/bbuck/glox/token"
+
+// Severity describes how serious a Diagnostic is.
+type Severity uint8
+
+// The severities a Diagnostic can be reported with.
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+)
+
+// String returns a friendly printable name for the Severity value.
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "Error"
+	case SeverityWarning:
+		return "Warning"
+	case SeverityNote:
+		return "Note"
+	}
+
+	return "UNKNOWN"
+}
+
+// Label attaches a message to a span of the source.
+type Label struct {
+	Span    token.Span
+	Message string
+}
+
+// Diagnostic is a single problem found in a program. Span is the primary
+// location of the problem, Labels point out any other related locations in
+// the source and Notes carry extra information that isn't tied to a
+// location.
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	Span     token.Span
+	Labels   []Label
+	Notes    []string
+}
+
+// NewError constructs a new error Diagnostic at the given span.
+func NewError(code string, span token.Span, msg string) *Diagnostic {
+	return &Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Message:  msg,
+		Span:     span,
+	}
+}
+
+// NewWarning constructs a new warning Diagnostic at the given span.
+func NewWarning(code string, span token.Span, msg string) *Diagnostic {
+	d := NewError(code, span, msg)
+	d.Severity = SeverityWarning
+
+	return d
+}
+
+// WithLabel adds a secondary label to the diagnostic and returns it to
+// allow chaining.
+func (d *Diagnostic) WithLabel(span token.Span, msg string) *Diagnostic {
+	d.Labels = append(d.Labels, Label{
+		Span:    span,
+		Message: msg,
+	})
+
+	return d
+}
+
+// WithNote adds a note to the diagnostic and returns it to allow chaining.
+func (d *Diagnostic) WithNote(msg string) *Diagnostic {
+	d.Notes = append(d.Notes, msg)
+
+	return d
+}
+
+// Line returns the line the diagnostic occurred on.
+func (d *Diagnostic) Line() uint {
+	return d.Span.Start.Line
+}
@@ -0,0 +1,55 @@
+package errs
+
+import "strings"
+
+// JSONDiagnostic is the machine readable form of a Diagnostic. Lines and
+// columns are 1 based, columns are counted in runes and end positions are
+// exclusive.
+type JSONDiagnostic struct {
+	File      string      `json:"file"`
+	Line      uint        `json:"line"`
+	Column    uint        `json:"column"`
+	EndLine   uint        `json:"end_line"`
+	EndColumn uint        `json:"end_column"`
+	Severity  string      `json:"severity"`
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Labels    []JSONLabel `json:"labels,omitempty"`
+	Notes     []string    `json:"notes,omitempty"`
+}
+
+// JSONLabel is the machine readable form of a Label.
+type JSONLabel struct {
+	Line      uint   `json:"line"`
+	Column    uint   `json:"column"`
+	EndLine   uint   `json:"end_line"`
+	EndColumn uint   `json:"end_column"`
+	Message   string `json:"message"`
+}
+
+// NewJSONDiagnostic converts the diagnostic into its machine readable form.
+func NewJSONDiagnostic(file string, d *Diagnostic) *JSONDiagnostic {
+	jd := &JSONDiagnostic{
+		File:      file,
+		Line:      d.Span.Start.Line,
+		Column:    d.Span.Start.Column,
+		EndLine:   d.Span.End.Line,
+		EndColumn: d.Span.End.Column,
+		Severity:  strings.ToLower(d.Severity.String()),
+		Code:      d.Code,
+		Message:   d.Message,
+		Notes:     d.Notes,
+	}
+
+	for _, l := range d.Labels {
+		jd.Labels = append(jd.Labels, JSONLabel{
+			Line:      l.Span.Start.Line,
+			Column:    l.Span.Start.Column,
+			EndLine:   l.Span.End.Line,
+			EndColumn: l.Span.End.Column,
+			Message:   l.Message,
+		})
+	}
+
+	return jd
+}
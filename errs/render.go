@@ -0,0 +1,130 @@
+package errs
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bbuck/glox/token"
+)
+
+// ANSI escape sequences used when rendering with color.
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorCyan   = "\x1b[36m"
+)
+
+// Render writes the diagnostic to w in a human readable form. The header
+// keeps the familiar `[line N] Error: message` form and, when the source the
+// diagnostic refers to is given, it is followed by the offending source
+// lines with the exact columns underlined. The primary span is marked with
+// carets and secondary labels with dashes. If color is true the output
+// includes ANSI color codes.
+func Render(w io.Writer, d *Diagnostic, source string, color bool) {
+	r := &renderer{
+		w:     w,
+		lines: strings.Split(source, "\n"),
+		color: color,
+	}
+	if source == "" {
+		r.lines = nil
+	}
+
+	r.header(d)
+
+	r.gutter = len(strconv.Itoa(int(d.Line())))
+	for _, l := range d.Labels {
+		if n := len(strconv.Itoa(int(l.Span.Start.Line))); n > r.gutter {
+			r.gutter = n
+		}
+	}
+
+	r.excerpt(d.Span, '^', "", severityColor(d.Severity))
+	for _, l := range d.Labels {
+		r.excerpt(l.Span, '-', l.Message, colorCyan)
+	}
+
+	for _, note := range d.Notes {
+		fmt.Fprintf(w, "%s %s note: %s\n", strings.Repeat(" ", r.gutter), r.paint(colorBlue, "="), note)
+	}
+}
+
+type renderer struct {
+	w      io.Writer
+	lines  []string
+	color  bool
+	gutter int
+}
+
+func (r *renderer) header(d *Diagnostic) {
+	kind := d.Severity.String()
+	if d.Code != "" {
+		kind += "[" + d.Code + "]"
+	}
+
+	fmt.Fprintf(r.w, "[line %d] %s: %s\n", d.Line(), r.paint(colorBold+severityColor(d.Severity), kind), r.paint(colorBold, d.Message))
+}
+
+// excerpt prints the first source line of the span with the spanned columns
+// underlined. Nothing is printed if the line isn't part of the source.
+func (r *renderer) excerpt(span token.Span, mark rune, msg string, color string) {
+	line := span.Start.Line
+	if span.Start.Column == 0 || line == 0 || int(line) > len(r.lines) {
+		return
+	}
+
+	text := []rune(strings.TrimSuffix(r.lines[line-1], "\r"))
+
+	start := int(span.Start.Column)
+	end := len(text) + 1
+	if span.End.Line == line {
+		end = int(span.End.Column)
+	}
+	if end <= start {
+		end = start + 1
+	}
+
+	// keep tabs in the padding so the marks line up with the source
+	pad := make([]rune, 0, start)
+	for i := 0; i < start-1; i++ {
+		if i < len(text) && text[i] == '\t' {
+			pad = append(pad, '\t')
+		} else {
+			pad = append(pad, ' ')
+		}
+	}
+
+	marks := strings.Repeat(string(mark), end-start)
+	if msg != "" {
+		marks += " " + msg
+	}
+
+	number := fmt.Sprintf("%*d", r.gutter, line)
+	bar := r.paint(colorBlue, "|")
+	fmt.Fprintf(r.w, "%s %s %s\n", r.paint(colorBlue, number), bar, string(text))
+	fmt.Fprintf(r.w, "%s %s %s%s\n", strings.Repeat(" ", r.gutter), bar, string(pad), r.paint(color, marks))
+}
+
+func (r *renderer) paint(color, text string) string {
+	if !r.color {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+func severityColor(s Severity) string {
+	switch s {
+	case SeverityWarning:
+		return colorYellow
+	case SeverityNote:
+		return colorBlue
+	}
+
+	return colorRed
+}
@@ -0,0 +1,46 @@
+package errs_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/token"
+)
+
+func Test_Render(t *testing.T) {
+	source := "var a = 1;\n\tprint a +* 2;\n"
+	d := errs.NewError(errs.CodeSyntax, span(2, 11, 2, 12), "Expected expression").
+		WithLabel(span(2, 8, 2, 11), "left operand").
+		WithNote("a note")
+
+	buf := new(bytes.Buffer)
+	errs.Render(buf, d, source, false)
+
+	expected := "[line 2] Error[P001]: Expected expression\n" +
+		"2 | \tprint a +* 2;\n" +
+		"  | \t         ^\n" +
+		"2 | \tprint a +* 2;\n" +
+		"  | \t      --- left operand\n" +
+		"  = note: a note\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q but got %q", expected, buf.String())
+	}
+}
+
+func Test_Render_WithoutSource(t *testing.T) {
+	buf := new(bytes.Buffer)
+	errs.Render(buf, errs.NewError("", span(4, 1, 4, 2), "Oops"), "", false)
+
+	expected := "[line 4] Error: Oops\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q but got %q", expected, buf.String())
+	}
+}
+
+func span(sl, sc, el, ec uint) token.Span {
+	return token.Span{
+		Start: token.Position{Line: sl, Column: sc},
+		End:   token.Position{Line: el, Column: ec},
+	}
+}
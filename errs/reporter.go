@@ -0,0 +1,115 @@
+package errs
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// Reporter receives every diagnostic produced while processing a program.
+// The scanner, parser, resolver and interpreter are each given a Reporter
+// rather than writing diagnostics out themselves so that users of the
+// library decide where diagnostics go.
+type Reporter interface {
+	Report(d *Diagnostic)
+}
+
+// TextReporter renders diagnostics in human readable form, with excerpts
+// from the source, to a writer.
+type TextReporter struct {
+	W      io.Writer
+	Source string
+	Color  bool
+}
+
+// NewTextReporter constructs a TextReporter writing to w. The source is used
+// to show excerpts of the lines diagnostics refer to.
+func NewTextReporter(w io.Writer, source string, color bool) *TextReporter {
+	return &TextReporter{
+		W:      w,
+		Source: source,
+		Color:  color,
+	}
+}
+
+// NewStderrReporter constructs a TextReporter writing to os.Stderr, in color
+// when os.Stderr is a terminal.
+func NewStderrReporter(source string) *TextReporter {
+	return NewTextReporter(os.Stderr, source, useColor(os.Stderr))
+}
+
+// Report renders the diagnostic to the writer.
+func (t *TextReporter) Report(d *Diagnostic) {
+	Render(t.W, d, t.Source, t.Color)
+}
+
+// Collector keeps every reported diagnostic so they can be inspected as
+// values.
+type Collector struct {
+	Diagnostics []*Diagnostic
+}
+
+// NewCollector constructs an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		Diagnostics: make([]*Diagnostic, 0),
+	}
+}
+
+// Report stores the diagnostic.
+func (c *Collector) Report(d *Diagnostic) {
+	c.Diagnostics = append(c.Diagnostics, d)
+}
+
+// HasErrors returns true if any collected diagnostic is an error.
+func (c *Collector) HasErrors() bool {
+	for _, d := range c.Diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// JSONReporter writes each diagnostic to a writer as a single line JSON
+// object, see JSONDiagnostic for the format. If a write fails the error is
+// kept in Err and no further diagnostics are written.
+type JSONReporter struct {
+	File string
+	Err  error
+	enc  *json.Encoder
+}
+
+// NewJSONReporter constructs a JSONReporter writing to w. The file name is
+// included in every diagnostic written.
+func NewJSONReporter(w io.Writer, file string) *JSONReporter {
+	return &JSONReporter{
+		File: file,
+		enc:  json.NewEncoder(w),
+	}
+}
+
+// Report encodes the diagnostic as JSON and writes it out.
+func (j *JSONReporter) Report(d *Diagnostic) {
+	if j.Err != nil {
+		return
+	}
+
+	j.Err = j.enc.Encode(NewJSONDiagnostic(j.File, d))
+}
+
+// useColor reports whether output to f should be colored, which is only the
+// case for terminals and when the NO_COLOR convention isn't in effect.
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,63 @@
+package errs_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/bbuck/glox/errs"
+)
+
+func Test_Collector(t *testing.T) {
+	c := errs.NewCollector()
+	if c.HasErrors() {
+		t.Errorf("expected an empty collector to have no errors")
+	}
+
+	d := errs.NewError(errs.CodeSyntax, span(1, 1, 1, 2), "Oops")
+	c.Report(d)
+
+	if !c.HasErrors() {
+		t.Errorf("expected the collector to have errors")
+	}
+
+	if len(c.Diagnostics) != 1 || c.Diagnostics[0] != d {
+		t.Errorf("expected the reported diagnostic to be collected")
+	}
+}
+
+func Test_JSONReporter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	r := errs.NewJSONReporter(buf, "main.lox")
+	r.Report(errs.NewError(errs.CodeSyntax, span(2, 3, 2, 4), "Expected expression").WithNote("a note"))
+
+	expected := `{"file":"main.lox","line":2,"column":3,"end_line":2,"end_column":4,"severity":"error","code":"P001","message":"Expected expression","notes":["a note"]}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q but got %q", expected, buf.String())
+	}
+}
+
+func Test_JSONReporter_WriteError(t *testing.T) {
+	w := &failingWriter{err: errors.New("disk full")}
+	r := errs.NewJSONReporter(w, "main.lox")
+	r.Report(errs.NewError(errs.CodeSyntax, span(1, 1, 1, 2), "First"))
+	r.Report(errs.NewError(errs.CodeSyntax, span(2, 1, 2, 2), "Second"))
+
+	if r.Err != w.err {
+		t.Errorf("expected the write error to be kept but got %v", r.Err)
+	}
+
+	if w.writes != 1 {
+		t.Errorf("expected nothing written after the error but got %d writes", w.writes)
+	}
+}
+
+type failingWriter struct {
+	err    error
+	writes int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	f.writes++
+	return 0, f.err
+}
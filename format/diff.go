@@ -0,0 +1,227 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// an edit is a single line of a diff, kind is ' ' for a line both texts
+// share, '-' for a line removed from the first and '+' for a line added in
+// the second
+type edit struct {
+	kind byte
+	line string
+}
+
+// Diff returns the changes needed to turn a into b as a unified diff, with
+// the texts labelled by the names given. It returns an empty string if the
+// texts are the same.
+func Diff(aName, bName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	edits := lineEdits(splitLines(a), splitLines(b))
+
+	// the line each edit falls on in a and b
+	aLines := make([]int, len(edits)+1)
+	bLines := make([]int, len(edits)+1)
+	for i, e := range edits {
+		aLines[i+1], bLines[i+1] = aLines[i], bLines[i]
+		if e.kind != '+' {
+			aLines[i+1]++
+		}
+		if e.kind != '-' {
+			bLines[i+1]++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+	for i := 0; i < len(edits); {
+		for i < len(edits) && edits[i].kind == ' ' {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		// take in every change separated by too few unchanged lines to
+		// be worth splitting the hunk
+		end := i
+		for j := i; j < len(edits) && j-end < 2*diffContext; j++ {
+			if edits[j].kind != ' ' {
+				end = j + 1
+			}
+		}
+
+		start := max(i-diffContext, 0)
+		end = min(end+diffContext, len(edits))
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aLines[start], aLines[end]), hunkRange(bLines[start], bLines[end]))
+		for _, e := range edits[start:end] {
+			sb.WriteByte(e.kind)
+			sb.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+// hunkRange formats the lines from start up to end for a hunk header,
+// lines are numbered from 1 except in an empty range, which names the line
+// before it.
+func hunkRange(start, end int) string {
+	if end-start == 1 {
+		return fmt.Sprint(start + 1)
+	}
+
+	if end == start {
+		return fmt.Sprintf("%d,0", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, end-start)
+}
+
+// lineEdits finds the shortest list of edits turning a into b, using the
+// linear space version of Myers' algorithm from "An O(ND) Difference
+// Algorithm and Its Variations". It takes time proportional to the length
+// of the texts times the number of edits, which is small for a formatted
+// file and its source.
+func lineEdits(a, b []string) []edit {
+	edits := diffLines(make([]edit, 0, len(a)+len(b)), a, b)
+
+	// list the lines removed by each change before the lines added
+	for i := 0; i < len(edits); i++ {
+		j := i
+		for j < len(edits) && edits[j].kind != ' ' {
+			j++
+		}
+
+		change := edits[i:j]
+		sort.SliceStable(change, func(x, y int) bool {
+			return change[x].kind == '-' && change[y].kind == '+'
+		})
+		i = j
+	}
+
+	return edits
+}
+
+// diffLines appends the edits turning a into b. The lines they start and
+// end with are set aside, then what's left is split around its middle
+// snake, the run of common lines halfway along the shortest edit script,
+// and each side is diffed in turn.
+func diffLines(edits []edit, a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits = appendEdits(edits, ' ', a[:prefix])
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	switch {
+	case len(midA) == 0:
+		edits = appendEdits(edits, '+', midB)
+	case len(midB) == 0:
+		edits = appendEdits(edits, '-', midA)
+	default:
+		// with the common ends removed there are at least two edits, so
+		// both sides of the snake are smaller than the whole
+		x, y, u, v := middleSnake(midA, midB)
+		edits = diffLines(edits, midA[:x], midB[:y])
+		edits = appendEdits(edits, ' ', midA[x:u])
+		edits = diffLines(edits, midA[u:], midB[v:])
+	}
+
+	return appendEdits(edits, ' ', a[len(a)-suffix:])
+}
+
+// middleSnake searches for the shortest edit script from both ends of the
+// texts at once, returning where the search paths meet: the run of common
+// lines from a[x], b[y] up to a[u], b[v]. A path's diagonal k is the
+// number of lines removed less the number added, and forward[k] and
+// backward[k] hold how far along a the furthest path on each diagonal has
+// got, the backward paths counting from the ends of the texts.
+func middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y = x - k
+			u, v = x, y
+			for u < n && v < m && a[u] == b[v] {
+				u++
+				v++
+			}
+			forward[offset+k] = u
+
+			// the backward path on the same diagonal as this one went
+			// one edit fewer last time round
+			if c := delta - k; delta%2 != 0 && c >= -(d-1) && c <= d-1 && u+backward[offset+c] >= n {
+				return x, y, u, v
+			}
+		}
+
+		for c := -d; c <= d; c += 2 {
+			if c == -d || (c != d && backward[offset+c-1] < backward[offset+c+1]) {
+				x = backward[offset+c+1]
+			} else {
+				x = backward[offset+c-1] + 1
+			}
+			y = x - c
+			u, v = x, y
+			for u < n && v < m && a[n-1-u] == b[m-1-v] {
+				u++
+				v++
+			}
+			backward[offset+c] = u
+
+			if k := delta - c; delta%2 == 0 && k >= -d && k <= d && u+forward[offset+k] >= n {
+				return n - u, m - v, n - x, m - y
+			}
+		}
+	}
+
+	panic("format: no middle snake between texts")
+}
+
+func appendEdits(edits []edit, kind byte, lines []string) []edit {
+	for _, line := range lines {
+		edits = append(edits, edit{kind, line})
+	}
+
+	return edits
+}
+
+// splitLines splits text into lines, each keeping its newline so a missing
+// newline at the end of the text counts as a difference.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
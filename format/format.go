@@ -0,0 +1,316 @@
+// Package format rewrites Lox source in a single canonical style, so that
+// formatting is never a matter of taste in code review.
+//
+// The formatter works from the tokens of the program, scanned losslessly so
+// comments survive, and lays them out again: blocks are indented two spaces,
+// each statement starts a new line, binary operators are surrounded by
+// spaces while unary operators hug their operand, and blank lines between
+// statements are kept but never doubled up. Parentheses are only printed
+// where the source has them. The program is parsed first, and source that
+// doesn't parse is never formatted.
+package format
+
+import (
+	"strings"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/scanner"
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/parser"
+)
+
+// indentation is written once for each level of nesting.
+const indentation = "  "
+
+// Source formats the Lox program in src. Any errors found scanning or
+// parsing the program are reported to the reporter and returned, in which
+// case src is not formatted.
+func Source(src string, reporter errs.Reporter) (string, error) {
+	s := scanner.New(src, reporter)
+	s.Lossless = true
+	if s.ScanTokens() {
+		return "", scanner.ScanError
+	}
+
+	p := parser.New(s.Tokens(), reporter)
+	stmts := p.ParseProgram()
+	if stmts == nil {
+		return "", p.Err
+	}
+
+	toks := s.Tokens()
+	f := &formatter{
+		unary:      findUnaryOperators(stmts),
+		conditions: findConditions(toks),
+	}
+	for i, tok := range toks {
+		f.leading(tok)
+		if tok.Type == token.EOF {
+			break
+		}
+
+		f.token(tok)
+		f.trailing(tok)
+		f.after(tok, toks[i+1])
+	}
+
+	out := f.out.String()
+	if out == "" {
+		return "", nil
+	}
+
+	return out + "\n", nil
+}
+
+type formatter struct {
+	out        strings.Builder
+	unary      map[*token.T]bool
+	conditions map[*token.T]bool
+
+	// the last token written
+	prev *token.T
+
+	// the nesting of blocks and of parentheses within the current block,
+	// the parentheses open in each enclosing block are kept in outer
+	indent int
+	parens int
+	outer  []int
+
+	// newlines to write before the next text, 2 leaving a blank line, and
+	// whether that text continues a statement broken by a comment
+	newlines  int
+	continued bool
+}
+
+// leading writes the comments before tok, each on its own line unless it
+// shared a line with the code before it in the source.
+func (f *formatter) leading(tok *token.T) {
+	newlines := 0
+	for _, tr := range tok.Leading {
+		switch tr.Kind {
+		case token.NewlineTrivia:
+			newlines += strings.Count(tr.Text, "\n")
+		case token.LineCommentTrivia, token.BlockCommentTrivia:
+			if newlines > 0 || f.prev == nil {
+				f.breakLine(newlines)
+				f.write(comment(tr), false)
+				f.newline(1)
+			} else {
+				f.write(comment(tr), true)
+				if tr.Kind == token.LineCommentTrivia {
+					f.breakLine(1)
+				}
+			}
+			newlines = 0
+		}
+	}
+
+	if newlines > 1 && tok.Type != token.RightBrace {
+		f.blankLine()
+	}
+}
+
+// breakLine moves to a new line for a comment that started a line in the
+// source, keeping a blank line before it if there was one. If the layout
+// had the comment sharing a line with code then the line after it
+// continues that statement.
+func (f *formatter) breakLine(newlines int) {
+	if f.newlines == 0 && f.prev != nil {
+		f.continued = true
+	}
+
+	f.newline(1)
+	if newlines > 1 {
+		f.blankLine()
+	}
+}
+
+// blankLine turns the newline already asked for into a blank line, unless
+// it would be the first line of a block.
+func (f *formatter) blankLine() {
+	if f.newlines > 0 && (f.prev == nil || f.prev.Type != token.LeftBrace) {
+		f.newline(2)
+	}
+}
+
+// token writes tok, closing the block first if it ends one.
+func (f *formatter) token(tok *token.T) {
+	if tok.Type == token.RightBrace {
+		f.indent--
+		f.parens = f.outer[len(f.outer)-1]
+		f.outer = f.outer[:len(f.outer)-1]
+		if f.prev.Type != token.LeftBrace {
+			f.newline(1)
+		}
+	}
+
+	f.write(tok.Text, f.spaceBefore(tok))
+	f.prev = tok
+}
+
+// trailing writes the comments that followed tok on its line.
+func (f *formatter) trailing(tok *token.T) {
+	for _, tr := range tok.Trailing {
+		if tr.Kind == token.LineCommentTrivia || tr.Kind == token.BlockCommentTrivia {
+			f.write(comment(tr), tok.Type != token.LeftParen && tok.Type != token.Dot)
+		}
+
+		if tr.Kind == token.LineCommentTrivia {
+			f.breakLine(1)
+		}
+	}
+}
+
+// after decides what follows tok, given the next token, opening any block
+// that tok starts.
+func (f *formatter) after(tok, next *token.T) {
+	newlines := 0
+	switch tok.Type {
+	case token.LeftBrace:
+		f.indent++
+		f.outer = append(f.outer, f.parens)
+		f.parens = 0
+		if next.Type != token.RightBrace || hasComments(next.Leading) {
+			newlines = 1
+		}
+	case token.RightBrace:
+		switch next.Type {
+		case token.Else, token.RightParen, token.Comma, token.Semicolon:
+		default:
+			newlines = 1
+		}
+	case token.Semicolon:
+		if f.parens == 0 {
+			newlines = 1
+		}
+	case token.LeftParen:
+		f.parens++
+	case token.RightParen:
+		f.parens--
+	}
+
+	if newlines > 0 {
+		f.continued = false
+		f.newline(newlines)
+	}
+}
+
+// spaceBefore returns true if there should be a space between the last
+// token written and tok when they're on the same line.
+func (f *formatter) spaceBefore(tok *token.T) bool {
+	prev := f.prev
+	if prev == nil {
+		return false
+	}
+
+	switch tok.Type {
+	case token.Semicolon, token.Comma, token.RightParen, token.Dot:
+		return false
+	case token.RightBrace:
+		// an empty block
+		return prev.Type != token.LeftBrace
+	case token.LeftParen:
+		switch prev.Type {
+		case token.Identifier, token.This, token.String:
+			// a call
+			return false
+		case token.RightParen:
+			// a call, unless the parenthesis closed the condition of an
+			// if, while or for and this starts its body
+			return f.conditions[prev]
+		}
+	case token.String, token.Interpolation:
+		if strings.HasPrefix(tok.Text, "}") {
+			// the rest of an interpolated string
+			return false
+		}
+	}
+
+	switch prev.Type {
+	case token.LeftParen, token.Dot, token.Interpolation, token.Bang:
+		return false
+	case token.Minus:
+		return !f.unary[prev]
+	}
+
+	return true
+}
+
+// newline asks for at least n newlines before the next text is written.
+func (f *formatter) newline(n int) {
+	if n > f.newlines {
+		f.newlines = n
+	}
+}
+
+// write writes text, starting a new line first if one was asked for and
+// otherwise separating it from what came before with a space if space is
+// true.
+func (f *formatter) write(text string, space bool) {
+	switch {
+	case f.newlines > 0:
+		if f.out.Len() > 0 {
+			f.out.WriteString(strings.Repeat("\n", f.newlines))
+		}
+
+		indent := f.indent
+		if f.continued {
+			indent++
+		}
+		f.out.WriteString(strings.Repeat(indentation, indent))
+		f.newlines = 0
+	case space && f.out.Len() > 0:
+		f.out.WriteByte(' ')
+	}
+
+	f.out.WriteString(text)
+}
+
+// comment returns the text of a comment without trailing whitespace or
+// carriage returns.
+func comment(tr token.Trivia) string {
+	text := strings.Replace(tr.Text, "\r\n", "\n", -1)
+
+	return strings.TrimRight(text, " \t\r")
+}
+
+func hasComments(trivia []token.Trivia) bool {
+	for _, tr := range trivia {
+		if tr.Kind == token.LineCommentTrivia || tr.Kind == token.BlockCommentTrivia {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findConditions returns the right parentheses that close the condition, or
+// the clauses, of an if, while or for statement.
+func findConditions(toks []*token.T) map[*token.T]bool {
+	conditions := make(map[*token.T]bool)
+	for i, tok := range toks {
+		switch tok.Type {
+		case token.If, token.While, token.For:
+		default:
+			continue
+		}
+
+		depth := 0
+		for _, next := range toks[i+1:] {
+			if next.Type == token.LeftParen {
+				depth++
+			} else if next.Type == token.RightParen {
+				depth--
+			}
+
+			if depth == 0 {
+				if next.Type == token.RightParen {
+					conditions[next] = true
+				}
+				break
+			}
+		}
+	}
+
+	return conditions
+}
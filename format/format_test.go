@@ -0,0 +1,134 @@
+package format_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/format"
+)
+
+func Test_Source_Golden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.input"))
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("expected inputs in testdata but found %v (%v)", inputs, err)
+	}
+
+	for _, input := range inputs {
+		golden := strings.TrimSuffix(input, ".input") + ".golden"
+		expected := read(t, golden)
+
+		result, err := format.Source(read(t, input), errs.NewCollector())
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", input, err)
+			continue
+		}
+		expect(t, input, expected, result)
+
+		// formatting is idempotent
+		result, err = format.Source(expected, errs.NewCollector())
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", golden, err)
+			continue
+		}
+		expect(t, golden, expected, result)
+	}
+}
+
+func Test_Source_Errors(t *testing.T) {
+	sources := []string{
+		"print 1 +;",
+		"var a = @;",
+		`print "\q";`,
+	}
+
+	for _, source := range sources {
+		reporter := errs.NewCollector()
+		result, err := format.Source(source, reporter)
+		if err == nil || result != "" {
+			t.Errorf("%q: expected an error but got %q", source, result)
+		}
+
+		if !reporter.HasErrors() {
+			t.Errorf("%q: expected errors to be reported", source)
+		}
+	}
+}
+
+func Test_Diff(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven"
+	b := "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\ntwelve\n"
+
+	expect(t, "diff", `--- a
++++ b
+@@ -1,5 +1,5 @@
+ one
+-two
++2
+ three
+ four
+ five
+@@ -8,4 +8,5 @@
+ eight
+ nine
+ ten
+-eleven
+\ No newline at end of file
++eleven
++twelve
+`, format.Diff("a", "b", a, b))
+
+	expect(t, "no diff", "", format.Diff("a", "b", a, a))
+}
+
+// Test_Diff_LargeFile diffs texts far too long to compare with a full table
+// of every pair of lines.
+func Test_Diff_LargeFile(t *testing.T) {
+	lines := make([]string, 100000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("print %d;\n", i)
+	}
+	a := strings.Join(lines, "")
+	lines[50000] = "print x;\n"
+	lines = append(lines[:70000], lines[70001:]...)
+	b := strings.Join(lines, "")
+
+	expect(t, "diff", `--- a
++++ b
+@@ -49998,7 +49998,7 @@
+ print 49997;
+ print 49998;
+ print 49999;
+-print 50000;
++print x;
+ print 50001;
+ print 50002;
+ print 50003;
+@@ -69998,7 +69998,6 @@
+ print 69997;
+ print 69998;
+ print 69999;
+-print 70000;
+ print 70001;
+ print 70002;
+ print 70003;
+`, format.Diff("a", "b", a, b))
+}
+
+func read(t *testing.T, name string) string {
+	bytes, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("reading %s: %s", name, err)
+	}
+
+	return string(bytes)
+}
+
+func expect(t *testing.T, name, expected, result string) {
+	if result != expected {
+		t.Errorf("%s: expected\n%s\nbut got\n%s", name, expected, result)
+	}
+}
@@ -0,0 +1,139 @@
+package format
+
+import (
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/expr"
+	"github.com/bbuck/glox/tree/stmt"
+)
+
+// unaryFinder walks a program collecting the operator tokens of every
+// Unary expression, so a "-" can be told apart from a subtraction without
+// guessing from the tokens around it.
+type unaryFinder struct {
+	operators map[*token.T]bool
+}
+
+func findUnaryOperators(stmts []stmt.Stmt) map[*token.T]bool {
+	u := &unaryFinder{
+		operators: make(map[*token.T]bool),
+	}
+	u.statements(stmts)
+
+	return u.operators
+}
+
+func (u *unaryFinder) statements(stmts []stmt.Stmt) {
+	for _, st := range stmts {
+		if st != nil {
+			st.Accept(u)
+		}
+	}
+}
+
+func (u *unaryFinder) expressions(es ...expr.Expr) {
+	for _, e := range es {
+		if e != nil {
+			e.Accept(u)
+		}
+	}
+}
+
+func (u *unaryFinder) VisitExpression(e *stmt.Expression) {
+	u.expressions(e.Expression)
+}
+
+func (u *unaryFinder) VisitPrint(p *stmt.Print) {
+	u.expressions(p.Expression)
+}
+
+func (u *unaryFinder) VisitVar(v *stmt.Var) {
+	u.expressions(v.Initializer)
+}
+
+func (u *unaryFinder) VisitBlock(b *stmt.Block) {
+	u.statements(b.Statements)
+}
+
+func (u *unaryFinder) VisitIf(i *stmt.If) {
+	u.expressions(i.Condition)
+	u.statements([]stmt.Stmt{i.Then, i.Else})
+}
+
+func (u *unaryFinder) VisitWhile(w *stmt.While) {
+	u.expressions(w.Condition)
+	u.statements([]stmt.Stmt{w.Body})
+}
+
+func (u *unaryFinder) VisitFunction(f *stmt.Function) {
+	u.statements(f.Body)
+}
+
+func (u *unaryFinder) VisitReturn(r *stmt.Return) {
+	u.expressions(r.Value)
+}
+
+func (u *unaryFinder) VisitClass(c *stmt.Class) {
+	for _, m := range c.Methods {
+		m.Accept(u)
+	}
+}
+
+func (u *unaryFinder) VisitBinary(b *expr.Binary) {
+	u.expressions(b.Left, b.Right)
+}
+
+func (u *unaryFinder) VisitLiteral(l *expr.Literal) {}
+
+func (u *unaryFinder) VisitGrouping(g *expr.Grouping) {
+	u.expressions(g.Expression)
+}
+
+func (u *unaryFinder) VisitUnary(un *expr.Unary) {
+	u.operators[un.Operator] = true
+	u.expressions(un.Right)
+}
+
+func (u *unaryFinder) VisitSequenced(s *expr.Sequenced) {
+	u.expressions(s.Left, s.Right)
+}
+
+func (u *unaryFinder) VisitTernary(t *expr.Ternary) {
+	u.expressions(t.Condition, t.Positive, t.Negative)
+}
+
+func (u *unaryFinder) VisitVariable(v *expr.Variable) {}
+
+func (u *unaryFinder) VisitAssign(a *expr.Assign) {
+	u.expressions(a.Value)
+}
+
+func (u *unaryFinder) VisitLogical(l *expr.Logical) {
+	u.expressions(l.Left, l.Right)
+}
+
+func (u *unaryFinder) VisitCall(c *expr.Call) {
+	u.expressions(c.Callee)
+	u.expressions(c.Arguments...)
+}
+
+func (u *unaryFinder) VisitLambda(l *expr.Lambda) {
+	for _, st := range l.Body {
+		st.(stmt.Stmt).Accept(u)
+	}
+}
+
+func (u *unaryFinder) VisitGet(g *expr.Get) {
+	u.expressions(g.Object)
+}
+
+func (u *unaryFinder) VisitSet(s *expr.Set) {
+	u.expressions(s.Object, s.Value)
+}
+
+func (u *unaryFinder) VisitThis(t *expr.This) {}
+
+func (u *unaryFinder) VisitSuper(s *expr.Super) {}
+
+func (u *unaryFinder) VisitInterpolation(i *expr.Interpolation) {
+	u.expressions(i.Parts...)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/bbuck/glox/scanner"
+	"github.com/bbuck/glox/tree/expr"
+	"github.com/bbuck/glox/tree/parser"
+	"github.com/bbuck/glox/tree/printer"
+)
+
+// dumpFormats are the formats --dump-ast can print a syntax tree in.
+var dumpFormats = map[string]func(expr.Expr) (string, error){
+	"json":    dumpJSON,
+	"dot":     dumpGraph(printer.PrintDOT),
+	"mermaid": dumpGraph(printer.PrintMermaid),
+}
+
+// runDump parses the script, or standard input if there's no script, as a
+// single expression and prints its syntax tree in the format chosen with
+// --dump-ast. It returns the status the program should exit with.
+func runDump(args []string) int {
+	name := "<stdin>"
+	var data []byte
+	var err error
+	if len(args) == 1 {
+		name = args[0]
+		data, err = ioutil.ReadFile(name)
+	} else {
+		data, err = ioutil.ReadAll(os.Stdin)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Reading %s: %s\n", name, err)
+		return 1
+	}
+
+	contents := string(data)
+	reporter := newReporter(name, contents)
+	s := scanner.New(contents, reporter)
+	var ex expr.Expr
+	if !s.ScanTokens() {
+		ex = parser.New(s.Tokens(), reporter).Parse()
+	}
+
+	if diagnosticsLost(reporter) {
+		return 1
+	}
+
+	if ex == nil {
+		return 65
+	}
+
+	out, err := dumpFormats[*dumpAST](ex)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Dumping syntax tree: %s\n", err)
+		return 65
+	}
+
+	fmt.Println(out)
+
+	return 0
+}
+
+func dumpJSON(ex expr.Expr) (string, error) {
+	data, err := printer.ToJSON(ex)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// dumpGraph adapts a printer that can't fail to a dump format.
+func dumpGraph(printTree func(expr.Expr) string) func(expr.Expr) (string, error) {
+	return func(ex expr.Expr) (string, error) {
+		return printTree(ex), nil
+	}
+}
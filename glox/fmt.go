@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/bbuck/glox/format"
+)
+
+// runFmt runs the fmt subcommand with its arguments, returning the status
+// the program should exit with.
+func runFmt(prog string, args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := fs.Bool("w", false, "write the result to the source file instead of standard output")
+	diff := fs.Bool("d", false, "print a diff of the changes instead of the formatted source")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "USAGE: %s fmt [flags] [files]\n", prog)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "ERROR: Cannot use -w with standard input")
+			return 64
+		}
+
+		bytes, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: Reading standard input: %s\n", err)
+			return 1
+		}
+
+		return fmtSource("<stdin>", string(bytes), false, *diff)
+	}
+
+	status := 0
+	for _, name := range fs.Args() {
+		bytes, err := ioutil.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: Reading file: %s\n", err)
+			status = max(status, 1)
+			continue
+		}
+
+		status = max(status, fmtSource(name, string(bytes), *write, *diff))
+	}
+
+	return status
+}
+
+// fmtSource formats the source read from the named file and prints it,
+// shows a diff or writes it back to the file as the flags ask.
+func fmtSource(name, src string, write, diff bool) int {
+	reporter := newReporter(name, src)
+	out, err := format.Source(src, reporter)
+	if diagnosticsLost(reporter) {
+		return 1
+	}
+
+	if err != nil {
+		return 65
+	}
+
+	if diff {
+		fmt.Print(format.Diff(name+".orig", name, src, out))
+	}
+
+	if write && out != src {
+		info, err := os.Stat(name)
+		if err == nil {
+			err = ioutil.WriteFile(name, []byte(out), info.Mode().Perm())
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: Writing file: %s\n", err)
+			return 1
+		}
+	}
+
+	if !write && !diff {
+		fmt.Print(out)
+	}
+
+	return 0
+}
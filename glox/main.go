@@ -2,21 +2,54 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
 
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/interpreter"
+	"github.com/bbuck/glox/resolver"
 	"github.com/bbuck/glox/scanner"
+	"github.com/bbuck/glox/token"
 	"github.com/bbuck/glox/tree/parser"
-	"github.com/bbuck/glox/tree/printer"
+)
+
+var (
+	diagnostics = flag.String("diagnostics", "text", "format errors are reported in, either text or json")
+	dumpAST     = flag.String("dump-ast", "", "print the syntax tree of the script, a single expression, as json, dot or mermaid instead of running it")
 )
 
 func main() {
 	prog := os.Args[0]
-	args := os.Args[1:]
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "USAGE: %s [flags] [script]\n       %s [flags] fmt [-w] [-d] [files]\n", prog, prog)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *diagnostics != "text" && *diagnostics != "json" {
+		fmt.Fprintf(os.Stderr, "ERROR: Unknown diagnostics format %q\n", *diagnostics)
+		flag.Usage()
+		os.Exit(64)
+	}
+
+	if _, ok := dumpFormats[*dumpAST]; *dumpAST != "" && !ok {
+		fmt.Fprintf(os.Stderr, "ERROR: Unknown syntax tree format %q\n", *dumpAST)
+		flag.Usage()
+		os.Exit(64)
+	}
+
+	args := flag.Args()
+	if len(args) > 0 && args[0] == "fmt" {
+		os.Exit(runFmt(prog, args[1:]))
+	}
+
 	if len(args) > 1 {
-		fmt.Printf("USAGE: %s [script]\n", prog)
+		flag.Usage()
 		os.Exit(64)
+	} else if *dumpAST != "" {
+		os.Exit(runDump(args))
 	} else if len(args) == 1 {
 		if err := runFile(args[0]); err != nil {
 			fmt.Fprintf(os.Stderr, "ERROR: Reading file: %s", err.Error())
@@ -30,13 +63,26 @@ func main() {
 	}
 }
 
+var interp = interpreter.New(errs.NewStderrReporter(""))
+
 func runFile(name string) error {
 	bytes, err := ioutil.ReadFile(name)
 	if err != nil {
 		return err
 	}
 
-	if err = run(string(bytes)); err != nil {
+	contents := string(bytes)
+	reporter := newReporter(name, contents)
+	err = run(reporter, contents)
+	if diagnosticsLost(reporter) {
+		os.Exit(1)
+	}
+
+	if err != nil {
+		if _, ok := err.(*interpreter.RuntimeError); ok {
+			os.Exit(70)
+		}
+
 		os.Exit(65)
 	}
 
@@ -44,27 +90,106 @@ func runFile(name string) error {
 }
 
 func runPrompt() error {
-	scanner := bufio.NewScanner(os.Stdin)
+	lines := bufio.NewScanner(os.Stdin)
+	input := ""
 	for {
-		fmt.Print("> ")
-		if scanner.Scan() {
-			run(scanner.Text())
+		if input == "" {
+			fmt.Print("> ")
 		} else {
-			return scanner.Err()
+			fmt.Print(". ")
+		}
+
+		if !lines.Scan() {
+			return lines.Err()
+		}
+
+		input += lines.Text() + "\n"
+		if isIncomplete(input) {
+			continue
+		}
+
+		reporter := newReporter("<stdin>", input)
+		run(reporter, input)
+		if diagnosticsLost(reporter) {
+			os.Exit(1)
 		}
+
+		input = ""
 	}
 }
 
-func run(contents string) error {
-	scanner := scanner.New(contents)
-	scanner.ScanTokens()
-	p := parser.New(scanner.Tokens())
-	ex := p.Parse()
-	if ex == nil {
-		return nil
+// isIncomplete scans the input looking for signs that the user hasn't
+// finished typing it yet: an unterminated string or block comment, or more
+// opening brackets than closing.
+func isIncomplete(input string) bool {
+	reporter := errs.NewCollector()
+	s := scanner.New(input, reporter)
+
+	depth := 0
+	for tok := s.Next(); tok.Type != token.EOF; tok = s.Next() {
+		switch tok.Type {
+		case token.LeftParen, token.LeftBrace:
+			depth++
+		case token.RightParen, token.RightBrace:
+			depth--
+		}
 	}
 
-	fmt.Println(printer.Print(ex))
+	for _, d := range reporter.Diagnostics {
+		if d.Code == errs.CodeUnterminatedString || d.Code == errs.CodeUnterminatedComment {
+			return true
+		}
+	}
 
-	return nil
+	return depth > 0
+}
+
+// newReporter builds the reporter for the diagnostics format chosen on the
+// command line.
+func newReporter(name, contents string) errs.Reporter {
+	if *diagnostics == "json" {
+		return errs.NewJSONReporter(os.Stderr, name)
+	}
+
+	return errs.NewStderrReporter(contents)
+}
+
+// diagnosticsLost returns true, after saying why, if the reporter failed to
+// write out the diagnostics reported to it. Every command that reports
+// diagnostics checks this before deciding its exit status, so they're never
+// lost silently.
+func diagnosticsLost(reporter errs.Reporter) bool {
+	jr, ok := reporter.(*errs.JSONReporter)
+	if !ok || jr.Err == nil {
+		return false
+	}
+
+	fmt.Fprintf(os.Stderr, "ERROR: Writing diagnostics: %s\n", jr.Err.Error())
+
+	return true
+}
+
+// run scans, parses, resolves and interprets the contents, returning the
+// first error any of them had.
+func run(reporter errs.Reporter, contents string) error {
+	interp.Reporter = reporter
+
+	s := scanner.New(contents, reporter)
+	p := parser.NewStream(s, reporter)
+	stmts := p.ParseProgram()
+	if stmts == nil {
+		return p.Err
+	}
+
+	// the scanner reports errors without always breaking the tokens, such
+	// as an invalid escape in a string, so the parse alone can succeed
+	if s.HadError() {
+		return scanner.ScanError
+	}
+
+	if err := resolver.New(interp, reporter).Resolve(stmts); err != nil {
+		return err
+	}
+
+	return interp.Interpret(stmts)
 }
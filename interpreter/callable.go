@@ -0,0 +1,40 @@
+package interpreter
+
+import "time"
+
+// Callable is any Lox value that can be called like a function.
+type Callable interface {
+	// Arity is the number of arguments the callable expects to receive.
+	Arity() int
+
+	// Call invokes the callable with the already evaluated arguments and
+	// returns the resulting value.
+	Call(i *I, args []interface{}) (interface{}, error)
+}
+
+// nativeFunction is a function implemented in Go and exposed to Lox code.
+type nativeFunction struct {
+	arity int
+	fn    func(args []interface{}) interface{}
+}
+
+func (n *nativeFunction) Arity() int {
+	return n.arity
+}
+
+func (n *nativeFunction) Call(i *I, args []interface{}) (interface{}, error) {
+	return n.fn(args), nil
+}
+
+func (n *nativeFunction) String() string {
+	return "<native fn>"
+}
+
+func defineNatives(env *Environment) {
+	env.Define("clock", &nativeFunction{
+		arity: 0,
+		fn: func([]interface{}) interface{} {
+			return float64(time.Now().UnixNano()) / float64(time.Second)
+		},
+	})
+}
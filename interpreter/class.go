@@ -0,0 +1,90 @@
+package interpreter
+
+import "github.com/bbuck/glox/token"
+
+// Class is the runtime representation of a Lox class. Calling a class
+// constructs a new Instance of it.
+type Class struct {
+	name       string
+	superclass *Class
+	methods    map[string]*Function
+}
+
+// Arity for Class is the arity of the init method, or zero when the class
+// has no initializer.
+func (c *Class) Arity() int {
+	if init := c.findMethod("init"); init != nil {
+		return init.Arity()
+	}
+
+	return 0
+}
+
+// Call constructs a new instance of the class, running the initializer
+// with the arguments if the class defines one.
+func (c *Class) Call(i *I, args []interface{}) (interface{}, error) {
+	instance := &Instance{
+		class:  c,
+		fields: make(map[string]interface{}),
+	}
+
+	if init := c.findMethod("init"); init != nil {
+		if _, err := init.bind(instance).Call(i, args); err != nil {
+			return nil, err
+		}
+	}
+
+	return instance, nil
+}
+
+// String returns the name of the class.
+func (c *Class) String() string {
+	return c.name
+}
+
+// findMethod looks up the method by name on this class and then up the
+// superclass chain, returning nil if no class defines it.
+func (c *Class) findMethod(name string) *Function {
+	if method, ok := c.methods[name]; ok {
+		return method
+	}
+
+	if c.superclass != nil {
+		return c.superclass.findMethod(name)
+	}
+
+	return nil
+}
+
+// Instance is an object constructed from a Class holding its own set of
+// fields.
+type Instance struct {
+	class  *Class
+	fields map[string]interface{}
+}
+
+// Get returns the value of the field with the given name, or if there is
+// no such field the named method bound to this instance. An error is
+// returned if neither exists.
+func (in *Instance) Get(name *token.T) (interface{}, error) {
+	if value, ok := in.fields[name.Lexeme]; ok {
+		return value, nil
+	}
+
+	if method := in.class.findMethod(name.Lexeme); method != nil {
+		return method.bind(in), nil
+	}
+
+	return nil, runtimeError(name, "Undefined property '"+name.Lexeme+"'.", in)
+}
+
+// Set stores the value in the named field, creating the field if it does
+// not already exist.
+func (in *Instance) Set(name *token.T, value interface{}) {
+	in.fields[name.Lexeme] = value
+}
+
+// String returns a printable representation of the instance.
+func (in *Instance) String() string {
+	return in.class.name + " instance"
+}
@@ -0,0 +1,81 @@
+package interpreter
+
+import "github.com/bbuck/glox/token"
+
+// Environment stores the values bound to variable names within a single
+// scope. Scopes are chained together through their enclosing environment so
+// that lookups and assignments which miss in the current scope continue
+// outward until they reach the global scope.
+type Environment struct {
+	enclosing *Environment
+	values    map[string]interface{}
+}
+
+// NewEnvironment constructs a new, empty environment nested inside of the
+// given enclosing environment. A nil enclosing environment produces a top
+// level (global) scope.
+func NewEnvironment(enclosing *Environment) *Environment {
+	return &Environment{
+		enclosing: enclosing,
+		values:    make(map[string]interface{}),
+	}
+}
+
+// Define binds the name to the value in this environment, replacing any
+// previous binding with the same name.
+func (e *Environment) Define(name string, value interface{}) {
+	e.values[name] = value
+}
+
+// Get returns the value bound to the name, searching enclosing environments
+// if it is not found in this one. An error is returned if the variable has
+// never been defined.
+func (e *Environment) Get(name *token.T) (interface{}, error) {
+	if value, ok := e.values[name.Lexeme]; ok {
+		return value, nil
+	}
+
+	if e.enclosing != nil {
+		return e.enclosing.Get(name)
+	}
+
+	return nil, runtimeError(name, "Undefined variable '"+name.Lexeme+"'.")
+}
+
+// Assign updates the value of an existing variable, searching enclosing
+// environments if it is not found in this one. Unlike Define, Assign will
+// not create a new variable and returns an error if the name is unbound.
+func (e *Environment) Assign(name *token.T, value interface{}) error {
+	if _, ok := e.values[name.Lexeme]; ok {
+		e.values[name.Lexeme] = value
+		return nil
+	}
+
+	if e.enclosing != nil {
+		return e.enclosing.Assign(name, value)
+	}
+
+	return runtimeError(name, "Undefined variable '"+name.Lexeme+"'.")
+}
+
+// GetAt returns the value bound to the name in the environment exactly
+// distance scopes out from this one. The resolver guarantees the variable
+// exists there.
+func (e *Environment) GetAt(distance int, name string) interface{} {
+	return e.ancestor(distance).values[name]
+}
+
+// AssignAt updates the value bound to the name in the environment exactly
+// distance scopes out from this one.
+func (e *Environment) AssignAt(distance int, name *token.T, value interface{}) {
+	e.ancestor(distance).values[name.Lexeme] = value
+}
+
+func (e *Environment) ancestor(distance int) *Environment {
+	env := e
+	for i := 0; i < distance; i++ {
+		env = env.enclosing
+	}
+
+	return env
+}
@@ -0,0 +1,25 @@
+package interpreter
+
+import "github.com/bbuck/glox/token"
+
+// RuntimeError represents a failure evaluating the program. It carries the
+// token of the operation that failed along with the operand values that it
+// was given so callers can inspect what went wrong.
+type RuntimeError struct {
+	Token    *token.T
+	Message  string
+	Operands []interface{}
+}
+
+// Error returns the message describing the failure.
+func (r *RuntimeError) Error() string {
+	return r.Message
+}
+
+func runtimeError(tok *token.T, msg string, operands ...interface{}) error {
+	return &RuntimeError{
+		Token:    tok,
+		Message:  msg,
+		Operands: operands,
+	}
+}
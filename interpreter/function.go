@@ -0,0 +1,88 @@
+package interpreter
+
+import (
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/stmt"
+)
+
+// Function is a user defined Lox function along with the environment that
+// was active when it was declared, allowing it to close over variables in
+// the surrounding scopes.
+type Function struct {
+	name          string
+	params        []*token.T
+	body          []stmt.Stmt
+	closure       *Environment
+	isInitializer bool
+}
+
+// Arity for Function is the number of declared parameters.
+func (f *Function) Arity() int {
+	return len(f.params)
+}
+
+// Call binds the arguments to the parameter names in a new scope nested in
+// the closure and then executes the body of the function.
+func (f *Function) Call(i *I, args []interface{}) (interface{}, error) {
+	env := NewEnvironment(f.closure)
+	for idx, param := range f.params {
+		env.Define(param.Lexeme, args[idx])
+	}
+
+	i.executeBlock(f.body, env)
+
+	err := i.Err
+	i.Err = nil
+	if ret, ok := err.(*returnSignal); ok {
+		err = nil
+		if !f.isInitializer {
+			return ret.value, nil
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// initializers always produce the instance being initialized
+	if f.isInitializer {
+		return f.closure.GetAt(0, "this"), nil
+	}
+
+	return nil, nil
+}
+
+// bind creates a copy of the method whose closure has "this" defined as the
+// given instance.
+func (f *Function) bind(instance *Instance) *Function {
+	env := NewEnvironment(f.closure)
+	env.Define("this", instance)
+
+	return &Function{
+		name:          f.name,
+		params:        f.params,
+		body:          f.body,
+		closure:       env,
+		isInitializer: f.isInitializer,
+	}
+}
+
+// String returns a printable representation of the function.
+func (f *Function) String() string {
+	if f.name == "" {
+		return "<fn>"
+	}
+
+	return "<fn " + f.name + ">"
+}
+
+// returnSignal unwinds execution from a return statement up to the function
+// call that it returns from. It travels along the same path as errors so
+// every statement between the two stops executing.
+type returnSignal struct {
+	value interface{}
+}
+
+func (r *returnSignal) Error() string {
+	return "Cannot return from top-level code."
+}
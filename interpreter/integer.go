@@ -0,0 +1,74 @@
+package interpreter
+
+import (
+	"errors"
+	"math"
+
+	"github.com/bbuck/glox/token"
+)
+
+// Errors from integer arithmetic, their text is the message reported to the
+// user.
+var (
+	errIntegerOverflow = errors.New("Integer overflow.")
+	errDivisionByZero  = errors.New("Division by zero.")
+)
+
+// integerArithmetic applies the arithmetic operator op to two integers.
+// Division truncates toward zero and the result of modulo has the sign of
+// the dividend, overflowing the range of an int64 is an error rather than
+// wrapping around.
+func integerArithmetic(op token.Type, l, r int64) (int64, error) {
+	switch op {
+	case token.Plus:
+		if (r > 0 && l > math.MaxInt64-r) || (r < 0 && l < math.MinInt64-r) {
+			return 0, errIntegerOverflow
+		}
+
+		return l + r, nil
+	case token.Minus:
+		if (r < 0 && l > math.MaxInt64+r) || (r > 0 && l < math.MinInt64+r) {
+			return 0, errIntegerOverflow
+		}
+
+		return l - r, nil
+	case token.Star:
+		if l == 0 || r == 0 {
+			return 0, nil
+		}
+
+		product := l * r
+		if product/r != l || (l == -1 && r == math.MinInt64) || (r == -1 && l == math.MinInt64) {
+			return 0, errIntegerOverflow
+		}
+
+		return product, nil
+	case token.Slash:
+		if r == 0 {
+			return 0, errDivisionByZero
+		}
+
+		if l == math.MinInt64 && r == -1 {
+			return 0, errIntegerOverflow
+		}
+
+		return l / r, nil
+	case token.Percent:
+		if r == 0 {
+			return 0, errDivisionByZero
+		}
+
+		return l % r, nil
+	}
+
+	return 0, errors.New("Unknown integer operator.")
+}
+
+// negateInteger returns -r, or an error if r has no positive counterpart.
+func negateInteger(r int64) (int64, error) {
+	if r == math.MinInt64 {
+		return 0, errIntegerOverflow
+	}
+
+	return -r, nil
+}
@@ -0,0 +1,612 @@
+package interpreter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/expr"
+	"github.com/bbuck/glox/tree/stmt"
+)
+
+// I is a tree walking interpreter for Lox programs. It executes each
+// statement in turn, visiting each node of the expression trees they contain
+// and reducing them down to a single runtime value. Lox values are
+// represented by the Go types nil, bool, int64, float64 and string.
+type I struct {
+	// Out is where the output of print statements is written.
+	Out io.Writer
+
+	// Reporter is told about runtime errors that stop execution.
+	Reporter errs.Reporter
+
+	globals *Environment
+	env     *Environment
+	locals  map[expr.Expr]int
+	value   interface{}
+	Err     error
+}
+
+// New constructs a new interpreter ready to execute programs, writing any
+// printed output to os.Stdout and reporting runtime errors to the reporter.
+func New(reporter errs.Reporter) *I {
+	globals := NewEnvironment(nil)
+	defineNatives(globals)
+
+	return &I{
+		Out:      os.Stdout,
+		Reporter: reporter,
+		globals:  globals,
+		env:      globals,
+		locals:   make(map[expr.Expr]int),
+	}
+}
+
+// Resolve records that the variable referenced by the expression was
+// declared depth scopes out from the scope the expression is evaluated in.
+// Any variable reference that is never resolved is treated as a global.
+func (i *I) Resolve(e expr.Expr, depth int) {
+	i.locals[e] = depth
+}
+
+// Interpret executes each statement in order, stopping at the first
+// statement that fails and returning the error describing the failure.
+func (i *I) Interpret(stmts []stmt.Stmt) error {
+	i.value = nil
+	i.Err = nil
+
+	for _, st := range stmts {
+		i.execute(st)
+		if i.Err != nil {
+			i.report(i.Err)
+			return i.Err
+		}
+	}
+
+	return nil
+}
+
+// Evaluate walks the given expression tree and returns the value it
+// produces. If evaluation fails then the returned value will be nil and the
+// error describing the failure will be returned.
+func (i *I) Evaluate(e expr.Expr) (interface{}, error) {
+	i.value = nil
+	i.Err = nil
+
+	val := i.evaluate(e)
+	if i.Err != nil {
+		i.report(i.Err)
+		return nil, i.Err
+	}
+
+	return val, nil
+}
+
+// VisitExpression evaluates the expression and discards the result.
+func (i *I) VisitExpression(e *stmt.Expression) {
+	i.evaluate(e.Expression)
+}
+
+// VisitPrint evaluates the expression and writes the result to Out.
+func (i *I) VisitPrint(p *stmt.Print) {
+	value := i.evaluate(p.Expression)
+	if i.Err != nil {
+		return
+	}
+
+	fmt.Fprintln(i.Out, Stringify(value))
+}
+
+// VisitVar evaluates the initializer, if there is one, and defines the new
+// variable in the current environment.
+func (i *I) VisitVar(vr *stmt.Var) {
+	var value interface{}
+	if vr.Initializer != nil {
+		value = i.evaluate(vr.Initializer)
+		if i.Err != nil {
+			return
+		}
+	}
+
+	i.env.Define(vr.Name.Lexeme, value)
+}
+
+// VisitBlock executes the statements within a new scope nested inside of
+// the current one.
+func (i *I) VisitBlock(b *stmt.Block) {
+	i.executeBlock(b.Statements, NewEnvironment(i.env))
+}
+
+// VisitIf executes the Then branch when the condition is truthy and the
+// Else branch, if present, when it is not.
+func (i *I) VisitIf(st *stmt.If) {
+	cond := i.evaluate(st.Condition)
+	if i.Err != nil {
+		return
+	}
+
+	if isTruthy(cond) {
+		i.execute(st.Then)
+	} else if st.Else != nil {
+		i.execute(st.Else)
+	}
+}
+
+// VisitWhile executes the body for as long as the condition is truthy.
+func (i *I) VisitWhile(w *stmt.While) {
+	for {
+		cond := i.evaluate(w.Condition)
+		if i.Err != nil || !isTruthy(cond) {
+			return
+		}
+
+		i.execute(w.Body)
+		if i.Err != nil {
+			return
+		}
+	}
+}
+
+// VisitFunction defines a new function, closing over the current
+// environment, bound to the declared name.
+func (i *I) VisitFunction(f *stmt.Function) {
+	i.env.Define(f.Name.Lexeme, &Function{
+		name:    f.Name.Lexeme,
+		params:  f.Params,
+		body:    f.Body,
+		closure: i.env,
+	})
+}
+
+// VisitReturn evaluates the return value and begins unwinding execution
+// back to the function call being returned from.
+func (i *I) VisitReturn(r *stmt.Return) {
+	var value interface{}
+	if r.Value != nil {
+		value = i.evaluate(r.Value)
+		if i.Err != nil {
+			return
+		}
+	}
+
+	i.Err = &returnSignal{value: value}
+}
+
+// VisitClass defines a new class bound to the declared name. When the class
+// has a superclass its methods close over an extra scope defining "super".
+func (i *I) VisitClass(c *stmt.Class) {
+	var superclass *Class
+	if c.Superclass != nil {
+		value := i.evaluate(c.Superclass)
+		if i.Err != nil {
+			return
+		}
+
+		var ok bool
+		if superclass, ok = value.(*Class); !ok {
+			i.Err = runtimeError(c.Superclass.Name, "Superclass must be a class.", value)
+			return
+		}
+	}
+
+	i.env.Define(c.Name.Lexeme, nil)
+
+	env := i.env
+	if superclass != nil {
+		env = NewEnvironment(i.env)
+		env.Define("super", superclass)
+	}
+
+	methods := make(map[string]*Function)
+	for _, m := range c.Methods {
+		methods[m.Name.Lexeme] = &Function{
+			name:          m.Name.Lexeme,
+			params:        m.Params,
+			body:          m.Body,
+			closure:       env,
+			isInitializer: m.Name.Lexeme == "init",
+		}
+	}
+
+	i.Err = i.env.Assign(c.Name, &Class{
+		name:       c.Name.Lexeme,
+		superclass: superclass,
+		methods:    methods,
+	})
+}
+
+// VisitBinary evaluates both operands and then applies the operator to them.
+func (i *I) VisitBinary(b *expr.Binary) {
+	left := i.evaluate(b.Left)
+	right := i.evaluate(b.Right)
+	if i.Err != nil {
+		return
+	}
+
+	i.binary(b.Operator, left, right)
+}
+
+// binary applies the binary operator to the values of its operands.
+func (i *I) binary(op *token.T, left, right interface{}) {
+	switch op.Type {
+	case token.Plus:
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				i.value = l + r
+				return
+			}
+		}
+
+		if _, _, ok := numberOperands(left, right); ok {
+			i.arithmetic(op, left, right)
+			return
+		}
+
+		i.Err = runtimeError(op, "Operands must be two numbers or two strings.", left, right)
+	case token.Minus, token.Star, token.Slash, token.Percent:
+		i.arithmetic(op, left, right)
+	case token.Greater, token.GreaterEqual, token.Less, token.LessEqual:
+		i.comparison(op, left, right)
+	case token.EqualEqual:
+		i.value = isEqual(left, right)
+	case token.BangEqual:
+		i.value = !isEqual(left, right)
+	default:
+		i.Err = runtimeError(op, "Unknown binary operator.", left, right)
+	}
+}
+
+// VisitUnary evaluates the operand and then applies the operator to it.
+func (i *I) VisitUnary(u *expr.Unary) {
+	right := i.evaluate(u.Right)
+	if i.Err != nil {
+		return
+	}
+
+	i.unary(u.Operator, right)
+}
+
+// unary applies the unary operator to the value of its operand.
+func (i *I) unary(op *token.T, right interface{}) {
+	switch op.Type {
+	case token.Minus:
+		switch r := right.(type) {
+		case int64:
+			value, err := negateInteger(r)
+			if err != nil {
+				i.Err = runtimeError(op, err.Error(), right)
+				return
+			}
+
+			i.value = value
+		case float64:
+			i.value = -r
+		default:
+			i.Err = runtimeError(op, "Operand must be a number.", right)
+		}
+	case token.Bang:
+		i.value = !isTruthy(right)
+	default:
+		i.Err = runtimeError(op, "Unknown unary operator.", right)
+	}
+}
+
+// VisitLiteral produces the value stored in the literal.
+func (i *I) VisitLiteral(l *expr.Literal) {
+	i.value = l.Value
+}
+
+// VisitGrouping produces the value of the wrapped expression.
+func (i *I) VisitGrouping(g *expr.Grouping) {
+	i.value = i.evaluate(g.Expression)
+}
+
+// VisitSequenced evaluates the left expression, discards the result, and
+// then produces the value of the right expression.
+func (i *I) VisitSequenced(s *expr.Sequenced) {
+	i.evaluate(s.Left)
+	i.value = i.evaluate(s.Right)
+}
+
+// VisitTernary evaluates the condition and then produces the value of only
+// the branch selected by the truthiness of the condition.
+func (i *I) VisitTernary(t *expr.Ternary) {
+	cond := i.evaluate(t.Condition)
+	if i.Err != nil {
+		return
+	}
+
+	if isTruthy(cond) {
+		i.value = i.evaluate(t.Positive)
+	} else {
+		i.value = i.evaluate(t.Negative)
+	}
+}
+
+// VisitVariable produces the value currently bound to the variable.
+func (i *I) VisitVariable(v *expr.Variable) {
+	i.value, i.Err = i.lookUpVariable(v.Name, v)
+}
+
+// VisitAssign evaluates the new value, stores it in the existing variable
+// and produces the assigned value.
+func (i *I) VisitAssign(a *expr.Assign) {
+	value := i.evaluate(a.Value)
+	if i.Err != nil {
+		return
+	}
+
+	if depth, ok := i.locals[a]; ok {
+		i.env.AssignAt(depth, a.Name, value)
+	} else if i.Err = i.globals.Assign(a.Name, value); i.Err != nil {
+		return
+	}
+
+	i.value = value
+}
+
+// VisitLogical evaluates the left operand and only evaluates the right
+// operand if the left does not decide the result. The operand value itself
+// is produced rather than a boolean.
+func (i *I) VisitLogical(l *expr.Logical) {
+	left := i.evaluate(l.Left)
+	if i.Err != nil {
+		return
+	}
+
+	if l.Operator.Type == token.Or {
+		if isTruthy(left) {
+			i.value = left
+			return
+		}
+	} else if !isTruthy(left) {
+		i.value = left
+		return
+	}
+
+	i.value = i.evaluate(l.Right)
+}
+
+// VisitCall evaluates the callee and arguments, in order, and then calls the
+// callee producing the value it returns.
+func (i *I) VisitCall(c *expr.Call) {
+	callee := i.evaluate(c.Callee)
+
+	args := make([]interface{}, 0, len(c.Arguments))
+	for _, arg := range c.Arguments {
+		args = append(args, i.evaluate(arg))
+	}
+
+	if i.Err != nil {
+		return
+	}
+
+	fn, ok := callee.(Callable)
+	if !ok {
+		i.Err = runtimeError(c.Paren, "Can only call functions and classes.", callee)
+		return
+	}
+
+	if len(args) != fn.Arity() {
+		i.Err = runtimeError(c.Paren, fmt.Sprintf("Expected %d arguments but got %d.", fn.Arity(), len(args)), args...)
+		return
+	}
+
+	i.value, i.Err = fn.Call(i, args)
+}
+
+// VisitLambda produces a new anonymous function closing over the current
+// environment.
+func (i *I) VisitLambda(l *expr.Lambda) {
+	body := make([]stmt.Stmt, len(l.Body))
+	for idx, st := range l.Body {
+		body[idx] = st.(stmt.Stmt)
+	}
+
+	i.value = &Function{
+		params:  l.Params,
+		body:    body,
+		closure: i.env,
+	}
+}
+
+// VisitGet produces the value of the named property on an instance.
+func (i *I) VisitGet(g *expr.Get) {
+	object := i.evaluate(g.Object)
+	if i.Err != nil {
+		return
+	}
+
+	instance, ok := object.(*Instance)
+	if !ok {
+		i.Err = runtimeError(g.Name, "Only instances have properties.", object)
+		return
+	}
+
+	i.value, i.Err = instance.Get(g.Name)
+}
+
+// VisitSet stores the value into the named field on an instance and
+// produces the assigned value.
+func (i *I) VisitSet(s *expr.Set) {
+	object := i.evaluate(s.Object)
+	if i.Err != nil {
+		return
+	}
+
+	instance, ok := object.(*Instance)
+	if !ok {
+		i.Err = runtimeError(s.Name, "Only instances have fields.", object)
+		return
+	}
+
+	value := i.evaluate(s.Value)
+	if i.Err != nil {
+		return
+	}
+
+	instance.Set(s.Name, value)
+	i.value = value
+}
+
+// VisitThis produces the instance the current method is bound to.
+func (i *I) VisitThis(t *expr.This) {
+	i.value, i.Err = i.lookUpVariable(t.Keyword, t)
+}
+
+// VisitSuper produces the named method from the superclass, bound to the
+// current instance.
+func (i *I) VisitSuper(s *expr.Super) {
+	// "this" is always bound in the scope just inside of the one
+	// binding "super"
+	depth := i.locals[s]
+	superclass := i.env.GetAt(depth, "super").(*Class)
+	this := i.env.GetAt(depth-1, "this")
+
+	method := superclass.findMethod(s.Method.Lexeme)
+	if method == nil {
+		i.Err = runtimeError(s.Method, "Undefined property '"+s.Method.Lexeme+"'.")
+		return
+	}
+
+	i.value = method.bind(this.(*Instance))
+}
+
+// VisitInterpolation evaluates each part of the string in order and
+// produces their printed values joined together.
+func (i *I) VisitInterpolation(in *expr.Interpolation) {
+	var sb strings.Builder
+	for _, part := range in.Parts {
+		value := i.evaluate(part)
+		if i.Err != nil {
+			return
+		}
+
+		sb.WriteString(Stringify(value))
+	}
+
+	i.value = sb.String()
+}
+
+func (i *I) report(err error) {
+	if rerr, ok := err.(*RuntimeError); ok {
+		i.Reporter.Report(errs.NewError(errs.CodeRuntime, rerr.Token.Span(), rerr.Message))
+	}
+}
+
+func (i *I) lookUpVariable(name *token.T, e expr.Expr) (interface{}, error) {
+	if depth, ok := i.locals[e]; ok {
+		return i.env.GetAt(depth, name.Lexeme), nil
+	}
+
+	return i.globals.Get(name)
+}
+
+func (i *I) execute(st stmt.Stmt) {
+	if i.Err != nil {
+		return
+	}
+
+	st.Accept(i)
+}
+
+func (i *I) executeBlock(stmts []stmt.Stmt, env *Environment) {
+	previous := i.env
+	defer func() {
+		i.env = previous
+	}()
+
+	i.env = env
+	for _, st := range stmts {
+		i.execute(st)
+		if i.Err != nil {
+			return
+		}
+	}
+}
+
+func (i *I) evaluate(e expr.Expr) interface{} {
+	if i.Err != nil {
+		return nil
+	}
+
+	i.value = nil
+	e.Accept(i)
+
+	if i.Err != nil {
+		return nil
+	}
+
+	return i.value
+}
+
+// arithmetic applies an arithmetic operator to two numbers. When both are
+// integers the result is an integer, otherwise any integer is promoted to a
+// float and the result is a float.
+func (i *I) arithmetic(op *token.T, left, right interface{}) {
+	if l, r, ok := integerOperands(left, right); ok {
+		value, err := integerArithmetic(op.Type, l, r)
+		if err != nil {
+			i.Err = runtimeError(op, err.Error(), left, right)
+			return
+		}
+
+		i.value = value
+		return
+	}
+
+	l, r, ok := i.checkNumberOperands(op, left, right)
+	if !ok {
+		return
+	}
+
+	switch op.Type {
+	case token.Plus:
+		i.value = l + r
+	case token.Minus:
+		i.value = l - r
+	case token.Star:
+		i.value = l * r
+	case token.Slash:
+		i.value = l / r
+	case token.Percent:
+		i.value = math.Mod(l, r)
+	}
+}
+
+// comparison applies a comparison operator to two numbers, integers are
+// compared exactly and otherwise both are compared as floats.
+func (i *I) comparison(op *token.T, left, right interface{}) {
+	var less, equal, greater bool
+	if l, r, ok := integerOperands(left, right); ok {
+		less, equal, greater = l < r, l == r, l > r
+	} else if l, r, ok := i.checkNumberOperands(op, left, right); ok {
+		less, equal, greater = l < r, l == r, l > r
+	} else {
+		return
+	}
+
+	switch op.Type {
+	case token.Greater:
+		i.value = greater
+	case token.GreaterEqual:
+		i.value = greater || equal
+	case token.Less:
+		i.value = less
+	case token.LessEqual:
+		i.value = less || equal
+	}
+}
+
+func (i *I) checkNumberOperands(op *token.T, left, right interface{}) (float64, float64, bool) {
+	l, r, ok := numberOperands(left, right)
+	if !ok {
+		i.Err = runtimeError(op, "Operands must be numbers.", left, right)
+	}
+
+	return l, r, ok
+}
@@ -0,0 +1,269 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/interpreter"
+	"github.com/bbuck/glox/resolver"
+	"github.com/bbuck/glox/scanner"
+	"github.com/bbuck/glox/tree/expr"
+	"github.com/bbuck/glox/tree/parser"
+)
+
+func Test_Evaluate(t *testing.T) {
+	tests := map[string]string{
+		"1 + 2 * 3":                           "7",
+		"(1 + 2) * 3":                         "9",
+		"10 / 4":                              "2",
+		"10.0 / 4":                            "2.5",
+		"-7 / 2":                              "-3",
+		"-7 % 3":                              "-1",
+		"7.5 % 2":                             "1.5",
+		"0x10 * 0b10":                         "32",
+		"1 + 0.5":                             "1.5",
+		"1 == 1.0":                            "true",
+		"3 > 2.5":                             "true",
+		"9007199254740993 > 9007199254740992": "true",
+		"-9223372036854775807 - 1":            "-9223372036854775808",
+		"-1 + 3":                              "2",
+		"-(2 * 3)":                            "-6",
+		"--4":                                 "4",
+		`"foo" + "bar"`:                       "foobar",
+		"1 < 2":                               "true",
+		"!nil":                                "true",
+		"!0":                                  "false",
+		"nil == nil":                          "true",
+		`1 == "1"`:                            "false",
+		"1, 2, 3":                             "3",
+		`1 > 2 ? "yes" : "no"`:                "no",
+		`"a\tb"`:                              "a\tb",
+		`"1 + 2 = ${1 + 2}!"`:                 "1 + 2 = 3!",
+		`"${nil}${"${true}"}"`:                "niltrue",
+	}
+
+	for source, expected := range tests {
+		value, err := interpreter.New(errs.NewCollector()).Evaluate(parse(t, source))
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", source, err)
+			continue
+		}
+
+		expect(t, expected, interpreter.Stringify(value))
+	}
+}
+
+func Test_Evaluate_Errors(t *testing.T) {
+	sources := []string{
+		`"a" - 1`,
+		`1 + "a"`,
+		`"a" < "b"`,
+		"9223372036854775807 + 1",
+		"-9223372036854775807 - 2",
+		"4611686018427387904 * 2",
+		"1 / 0",
+		"1 % 0",
+		"(-9223372036854775807 - 1) / -1",
+	}
+
+	for _, source := range sources {
+		reporter := errs.NewCollector()
+		_, err := interpreter.New(reporter).Evaluate(parse(t, source))
+		if err == nil {
+			t.Errorf("%q: expected an error but got none", source)
+			continue
+		}
+
+		rerr, ok := err.(*interpreter.RuntimeError)
+		if !ok {
+			t.Errorf("%q: expected a *RuntimeError but got %T", source, err)
+			continue
+		}
+
+		if rerr.Token == nil || rerr.Token.Line != 1 {
+			t.Errorf("%q: expected error token on line 1 but got %v", source, rerr.Token)
+		}
+
+		if len(rerr.Operands) != 2 {
+			t.Errorf("%q: expected 2 operands but got %d", source, len(rerr.Operands))
+		}
+
+		if len(reporter.Diagnostics) != 1 || reporter.Diagnostics[0].Code != errs.CodeRuntime {
+			t.Errorf("%q: expected a single runtime diagnostic but got %v", source, reporter.Diagnostics)
+		}
+	}
+}
+
+func Test_Interpret(t *testing.T) {
+	expect(t, "3\nfoo\n", run(t, `print 1 + 2; "ignored"; print "foo";`))
+}
+
+func Test_Interpret_Variables(t *testing.T) {
+	expect(t, "1\n3\n3\nnil\n", run(t, `
+		var a = 1;
+		print a;
+		var b;
+		a = b = 3;
+		print a;
+		print b;
+		var c;
+		print c;
+	`))
+}
+
+func Test_Interpret_ControlFlow(t *testing.T) {
+	expect(t, "inner\nouter\nyes\n0\n1\n2\n3\n2\n2\nnil\nhi\n", run(t, `
+		var a = "outer";
+		{
+			var a = "inner";
+			print a;
+		}
+		print a;
+
+		if (a == "outer") print "yes"; else print "no";
+
+		var i = 0;
+		while (i < 2) {
+			print i;
+			i = i + 1;
+		}
+
+		for (var j = 2; j < 4; j = j + 1) print j;
+
+		print 1 and 2 or 3;
+		print 2 or undefined;
+		print nil and undefined;
+		print false or "hi";
+	`))
+}
+
+func Test_Interpret_Functions(t *testing.T) {
+	expect(t, "3\nnil\n1\n2\n<fn add>\n6\n", run(t, `
+		fun add(a, b) {
+			return a + b;
+		}
+		print add(1, 2);
+
+		fun nothing() {}
+		print nothing();
+
+		fun counter() {
+			var i = 0;
+			fun count() {
+				i = i + 1;
+				return i;
+			}
+			return count;
+		}
+		var c = counter();
+		print c();
+		print c();
+		print add;
+
+		fun apply(f, v) { return f(v); }
+		print apply(fun (x) { return x * 2; }, 3);
+	`))
+}
+
+func Test_Interpret_Classes(t *testing.T) {
+	expect(t, "Point instance\n3\n7\nDoughnut\nFry until golden brown.\nPipe full of custard.\n", run(t, `
+		class Point {
+			init(x, y) {
+				this.x = x;
+				this.y = y;
+			}
+
+			sum() {
+				return this.x + this.y;
+			}
+		}
+
+		var p = Point(1, 2);
+		print p;
+		print p.sum();
+		p.x = 5;
+		var sum = p.sum;
+		print sum();
+
+		class Doughnut {
+			cook() {
+				print "Fry until golden brown.";
+			}
+		}
+
+		class BostonCream < Doughnut {
+			cook() {
+				super.cook();
+				print "Pipe full of custard.";
+			}
+		}
+
+		print Doughnut;
+		BostonCream().cook();
+	`))
+}
+
+func Test_Interpret_Closures(t *testing.T) {
+	expect(t, "global\nglobal\n", run(t, `
+		var a = "global";
+		{
+			fun showA() {
+				print a;
+			}
+
+			showA();
+			var a = "block";
+			showA();
+		}
+	`))
+}
+
+func Test_Interpret_Interpolation(t *testing.T) {
+	expect(t, "Hello, Lox!\n", run(t, `
+		var name = "Lox";
+		fun greet(n) { return "Hello, ${n}!"; }
+		print greet(name);
+	`))
+}
+
+func run(t *testing.T, source string) string {
+	s := scanner.New(source, errs.NewCollector())
+	s.ScanTokens()
+	p := parser.New(s.Tokens(), errs.NewCollector())
+	stmts := p.ParseProgram()
+	if stmts == nil {
+		t.Fatalf("%q: failed to parse", source)
+	}
+
+	out := new(bytes.Buffer)
+	i := interpreter.New(errs.NewCollector())
+	i.Out = out
+	if err := resolver.New(i, i.Reporter).Resolve(stmts); err != nil {
+		t.Fatalf("%q: failed to resolve", source)
+	}
+
+	if err := i.Interpret(stmts); err != nil {
+		t.Fatalf("%q: unexpected error %s", source, err)
+	}
+
+	return out.String()
+}
+
+func parse(t *testing.T, source string) expr.Expr {
+	s := scanner.New(source, errs.NewCollector())
+	s.ScanTokens()
+	p := parser.New(s.Tokens(), errs.NewCollector())
+	ex := p.Parse()
+	if ex == nil {
+		t.Fatalf("%q: failed to parse", source)
+	}
+
+	return ex
+}
+
+func expect(t *testing.T, expected, result string) {
+	if result != expected {
+		t.Errorf("expected %q but got %q", expected, result)
+	}
+}
@@ -0,0 +1,228 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bbuck/glox/token"
+)
+
+// The operators of an RPN program and the token each applies.
+var (
+	rpnBinary = map[string]token.Type{
+		"+":  token.Plus,
+		"-":  token.Minus,
+		"*":  token.Star,
+		"/":  token.Slash,
+		"%":  token.Percent,
+		"==": token.EqualEqual,
+		"!=": token.BangEqual,
+		"<":  token.Less,
+		"<=": token.LessEqual,
+		">":  token.Greater,
+		">=": token.GreaterEqual,
+	}
+	rpnUnary = map[string]token.Type{
+		"neg": token.Minus,
+		"!":   token.Bang,
+	}
+)
+
+// instruction is a single step of an RPN program, taking pops values off
+// the stack. Push instructions carry the value pushed, jumps the index of
+// the instruction they continue from and interpolate the number of values
+// it joins.
+type instruction struct {
+	text  string
+	op    string
+	pops  int
+	value interface{}
+	arg   int
+	tok   *token.T
+}
+
+// EvaluateRPN runs a program written by printer.PrintRPN on a stack machine
+// and returns the single value it leaves on the stack. Operators are applied
+// exactly as the tree walking interpreter applies them, so for the same
+// expression both produce the same value or the same error. Only programs
+// built from values are supported, the instructions that need variables or
+// objects are an error. Errors are returned without being reported.
+func (i *I) EvaluateRPN(program string) (interface{}, error) {
+	code, err := assembleRPN(program)
+	if err != nil {
+		return nil, err
+	}
+
+	var stack []interface{}
+	pop := func() interface{} {
+		value := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		return value
+	}
+
+	for pc := 0; pc < len(code); pc++ {
+		in := code[pc]
+		if len(stack) < in.pops {
+			return nil, fmt.Errorf("Stack underflow at %q.", in.text)
+		}
+
+		i.value = nil
+		i.Err = nil
+		switch in.op {
+		case "push":
+			stack = append(stack, in.value)
+		case "binary":
+			right := pop()
+			i.binary(in.tok, pop(), right)
+		case "unary":
+			i.unary(in.tok, pop())
+		case "dup":
+			stack = append(stack, stack[len(stack)-1])
+		case "pop":
+			pop()
+		case "jmp":
+			pc = in.arg
+		case "jf":
+			if !isTruthy(pop()) {
+				pc = in.arg
+			}
+		case "jt":
+			if isTruthy(pop()) {
+				pc = in.arg
+			}
+		case "interpolate":
+			var sb strings.Builder
+			for _, value := range stack[len(stack)-in.arg:] {
+				sb.WriteString(Stringify(value))
+			}
+			stack = append(stack[:len(stack)-in.arg], sb.String())
+		}
+
+		if i.Err != nil {
+			return nil, i.Err
+		}
+
+		if in.op == "binary" || in.op == "unary" {
+			stack = append(stack, i.value)
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("Expect 1 value left on the stack but found %d.", len(stack))
+	}
+
+	return stack[0], nil
+}
+
+// assembleRPN splits the program into instructions and resolves the labels
+// that the jumps refer to. Labels are kept as instructions that do nothing,
+// so a jump continues from the instruction after its label.
+func assembleRPN(program string) ([]*instruction, error) {
+	var code []*instruction
+	labels := make(map[string]int)
+	for program = strings.TrimSpace(program); program != ""; program = strings.TrimSpace(program) {
+		text := program
+		if program[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(program)
+			if err != nil {
+				return nil, errors.New("Unterminated string in RPN program.")
+			}
+
+			text = quoted
+		} else if end := strings.IndexFunc(program, unicode.IsSpace); end >= 0 {
+			text = program[:end]
+		}
+		program = program[len(text):]
+
+		in, err := decodeRPN(text)
+		if err != nil {
+			return nil, err
+		}
+
+		if in.op == "label" {
+			labels[strings.TrimSuffix(text, ":")] = len(code)
+		}
+		code = append(code, in)
+	}
+
+	for _, in := range code {
+		switch in.op {
+		case "jmp", "jf", "jt":
+			target, ok := labels[in.text[strings.IndexByte(in.text, ':')+1:]]
+			if !ok {
+				return nil, fmt.Errorf("Unknown label in %q.", in.text)
+			}
+
+			in.arg = target
+		}
+	}
+
+	return code, nil
+}
+
+// decodeRPN decodes the text of a single instruction.
+func decodeRPN(text string) (*instruction, error) {
+	in := &instruction{text: text, op: "push"}
+	if typ, ok := rpnBinary[text]; ok {
+		in.op, in.pops, in.tok = "binary", 2, token.New(typ, text, nil, 0)
+		return in, nil
+	}
+
+	if typ, ok := rpnUnary[text]; ok {
+		in.op, in.pops, in.tok = "unary", 1, token.New(typ, text, nil, 0)
+		return in, nil
+	}
+
+	switch {
+	case text == "true" || text == "false":
+		in.value = text == "true"
+	case text == "nil":
+	case text == "dup" || text == "pop":
+		in.op, in.pops = text, 1
+	case text[0] == '"':
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid string %s in RPN program.", text)
+		}
+
+		in.value = value
+	case text[0] >= '0' && text[0] <= '9':
+		if !strings.ContainsAny(text, ".eE") {
+			value, err := strconv.ParseInt(text, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid integer %q in RPN program.", text)
+			}
+
+			in.value = value
+			break
+		}
+
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid number %q in RPN program.", text)
+		}
+
+		in.value = value
+	case strings.HasSuffix(text, ":"):
+		in.op = "label"
+	case strings.HasPrefix(text, "jmp:"):
+		in.op = "jmp"
+	case strings.HasPrefix(text, "jf:"), strings.HasPrefix(text, "jt:"):
+		in.op, in.pops = text[:2], 1
+	case strings.HasPrefix(text, "interpolate:"):
+		n, err := strconv.Atoi(strings.TrimPrefix(text, "interpolate:"))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("Invalid instruction %q in RPN program.", text)
+		}
+
+		in.op, in.pops, in.arg = "interpolate", n, n
+	default:
+		return nil, fmt.Errorf("Unsupported instruction %q in RPN program.", text)
+	}
+
+	return in, nil
+}
@@ -0,0 +1,126 @@
+package interpreter_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/interpreter"
+	"github.com/bbuck/glox/tree/printer"
+)
+
+func Test_EvaluateRPN(t *testing.T) {
+	tests := map[string]string{
+		"1 2 +":                          "3",
+		"10.0 4 /":                       "2.5",
+		"1 neg":                          "-1",
+		`"a" pop "b"`:                    "b",
+		"true jf:L0 1 jmp:L1 L0: 2 L1:":  "1",
+		"false jf:L0 1 jmp:L1 L0: 2 L1:": "2",
+		"nil dup jt:L0 pop 3 L0:":        "3",
+		`"x = " 1 interpolate:2`:         "x = 1",
+	}
+
+	for program, expected := range tests {
+		value, err := interpreter.New(errs.NewCollector()).EvaluateRPN(program)
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", program, err)
+			continue
+		}
+
+		expect(t, expected, interpreter.Stringify(value))
+	}
+}
+
+func Test_EvaluateRPN_Errors(t *testing.T) {
+	tests := map[string]string{
+		"1 +":                  `Stack underflow at "+".`,
+		"1 2":                  "Expect 1 value left on the stack but found 2.",
+		"jmp:L0":               `Unknown label in "jmp:L0".`,
+		"load:a":               `Unsupported instruction "load:a" in RPN program.`,
+		`"abc`:                 "Unterminated string in RPN program.",
+		"99999999999999999999": `Invalid integer "99999999999999999999" in RPN program.`,
+		`"a" 1 -`:              "Operands must be numbers.",
+	}
+
+	for program, expected := range tests {
+		_, err := interpreter.New(errs.NewCollector()).EvaluateRPN(program)
+		if err == nil {
+			t.Errorf("%q: expected an error but got none", program)
+			continue
+		}
+
+		expect(t, expected, err.Error())
+	}
+}
+
+// Test_EvaluateRPN_CrossCheck evaluates random expressions with both the
+// tree walking interpreter and the stack machine, which must agree on the
+// value or the error.
+func Test_EvaluateRPN_CrossCheck(t *testing.T) {
+	sources := []string{
+		"nil and 1 / 0",
+		"true or 1 / 0",
+		"1 ? 2 : 1 / 0",
+		"nil ? 1 / 0 : 2",
+		`"${1 / 0}"`,
+		"(1, 2) + 3",
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		sources = append(sources, randomSource(r, 4))
+	}
+
+	for _, source := range sources {
+		e := parse(t, source)
+		program := printer.PrintRPN(e)
+
+		value, err := interpreter.New(errs.NewCollector()).Evaluate(e)
+		rvalue, rerr := interpreter.New(errs.NewCollector()).EvaluateRPN(program)
+		switch {
+		case err != nil && rerr != nil:
+			if err.Error() != rerr.Error() {
+				t.Errorf("%q: expected error %q but %q failed with %q", source, err, program, rerr)
+			}
+		case err != nil:
+			t.Errorf("%q: expected error %q but %q produced %v", source, err, program, rvalue)
+		case rerr != nil:
+			t.Errorf("%q: expected %v but %q failed with %q", source, value, program, rerr)
+		case interpreter.Stringify(value) != interpreter.Stringify(rvalue):
+			t.Errorf("%q: expected %v but %q produced %v", source, value, program, rvalue)
+		}
+	}
+}
+
+var (
+	sourceValues    = []string{"0", "1", "7", "9223372036854775807", "0.5", "2.0", `""`, `"a"`, "true", "false", "nil"}
+	sourceOperators = []string{"+", "-", "*", "/", "%", "==", "!=", "<", "<=", ">", ">=", "and", "or"}
+)
+
+// randomSource builds a random expression that needs no variables, with
+// every operation in parentheses.
+func randomSource(r *rand.Rand, depth int) string {
+	if depth == 0 || r.Intn(4) == 0 {
+		return sourceValues[r.Intn(len(sourceValues))]
+	}
+
+	depth--
+	switch r.Intn(6) {
+	case 0:
+		return []string{"-", "!"}[r.Intn(2)] + randomSource(r, depth)
+	case 1:
+		return "(" + randomSource(r, depth) + ", " + randomSource(r, depth) + ")"
+	case 2:
+		return "(" + randomSource(r, depth) + " ? " + randomSource(r, depth) + " : " + randomSource(r, depth) + ")"
+	case 3:
+		return `"<${` + randomSource(r, depth) + `}>"`
+	}
+
+	return strings.Join([]string{
+		"(" + randomSource(r, depth),
+		sourceOperators[r.Intn(len(sourceOperators))],
+		randomSource(r, depth) + ")",
+	}, " ")
+}
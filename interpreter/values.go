@@ -0,0 +1,81 @@
+package interpreter
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Stringify converts a Lox runtime value into the text that should be shown
+// to the user when the value is printed.
+func Stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return "<unknown>"
+}
+
+// nil and false are falsey, every other value is truthy
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	}
+
+	return true
+}
+
+// values of different types are never equal, nil is only equal to nil,
+// integers and floats are equal if they're the same number
+func isEqual(a, b interface{}) bool {
+	if l, r, ok := integerOperands(a, b); ok {
+		return l == r
+	}
+
+	if l, r, ok := numberOperands(a, b); ok {
+		return l == r
+	}
+
+	return a == b
+}
+
+// integerOperands returns both operands if they're both integers.
+func integerOperands(left, right interface{}) (int64, int64, bool) {
+	l, lok := left.(int64)
+	r, rok := right.(int64)
+
+	return l, r, lok && rok
+}
+
+// numberOperands returns both operands as floats if they're both numbers,
+// promoting any integer to a float.
+func numberOperands(left, right interface{}) (float64, float64, bool) {
+	l, lok := toFloat(left)
+	r, rok := toFloat(right)
+
+	return l, r, lok && rok
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,17 @@
+package resolver
+
+import (
+	"errors"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/token"
+)
+
+// ResolveError represents a static failure found while resolving the
+// program.
+var ResolveError = errors.New("Resolve Error")
+
+func (r *R) error(tok *token.T, code, msg string) {
+	r.reporter.Report(errs.NewError(code, tok.Span(), msg))
+	r.Err = ResolveError
+}
@@ -0,0 +1,368 @@
+package resolver
+
+import (
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/expr"
+	"github.com/bbuck/glox/tree/stmt"
+)
+
+// Interpreter is notified of how many scopes away from the innermost scope
+// each resolved variable reference was declared.
+type Interpreter interface {
+	Resolve(e expr.Expr, depth int)
+}
+
+type functionType uint8
+
+const (
+	noFunction functionType = iota
+	function
+	initializer
+	method
+)
+
+type classType uint8
+
+const (
+	noClass classType = iota
+	class
+	subclass
+)
+
+// R is a static pass over the syntax tree that binds each variable
+// reference to the scope it was declared in, before any code executes.
+// References that cannot be found in any local scope are assumed to be
+// globals and are not reported to the interpreter.
+type R struct {
+	interp          Interpreter
+	reporter        errs.Reporter
+	scopes          []map[string]bool
+	currentFunction functionType
+	currentClass    classType
+	Err             error
+}
+
+// New constructs a new resolver that will report resolved variable depths
+// to the given interpreter and any static errors to the reporter.
+func New(interp Interpreter, reporter errs.Reporter) *R {
+	return &R{
+		interp:   interp,
+		reporter: reporter,
+		scopes:   make([]map[string]bool, 0),
+	}
+}
+
+// Resolve walks each of the statements resolving every variable reference
+// inside of them. All static errors are reported and if any occurred an
+// error is returned.
+func (r *R) Resolve(stmts []stmt.Stmt) error {
+	r.Err = nil
+	r.resolveStatements(stmts)
+
+	return r.Err
+}
+
+// VisitExpression resolves the expression.
+func (r *R) VisitExpression(e *stmt.Expression) {
+	r.resolveExpr(e.Expression)
+}
+
+// VisitPrint resolves the printed expression.
+func (r *R) VisitPrint(p *stmt.Print) {
+	r.resolveExpr(p.Expression)
+}
+
+// VisitVar declares the variable, resolves the initializer and then marks
+// the variable as ready for use. Splitting declaration and definition lets
+// us catch a variable being read in its own initializer.
+func (r *R) VisitVar(v *stmt.Var) {
+	r.declare(v.Name)
+	if v.Initializer != nil {
+		r.resolveExpr(v.Initializer)
+	}
+	r.define(v.Name)
+}
+
+// VisitBlock resolves the statements within a new scope.
+func (r *R) VisitBlock(b *stmt.Block) {
+	r.beginScope()
+	r.resolveStatements(b.Statements)
+	r.endScope()
+}
+
+// VisitIf resolves the condition and both branches.
+func (r *R) VisitIf(i *stmt.If) {
+	r.resolveExpr(i.Condition)
+	r.resolveStmt(i.Then)
+	if i.Else != nil {
+		r.resolveStmt(i.Else)
+	}
+}
+
+// VisitWhile resolves the condition and the body.
+func (r *R) VisitWhile(w *stmt.While) {
+	r.resolveExpr(w.Condition)
+	r.resolveStmt(w.Body)
+}
+
+// VisitFunction defines the function name eagerly, so the function may
+// refer to itself, and then resolves the body.
+func (r *R) VisitFunction(f *stmt.Function) {
+	r.declare(f.Name)
+	r.define(f.Name)
+
+	r.resolveFunction(f.Params, f.Body, function)
+}
+
+// VisitReturn ensures the return is within a function and resolves the
+// returned value.
+func (r *R) VisitReturn(ret *stmt.Return) {
+	if r.currentFunction == noFunction {
+		r.error(ret.Keyword, errs.CodeTopLevelReturn, "Cannot return from top-level code")
+	}
+
+	if ret.Value != nil {
+		if r.currentFunction == initializer {
+			r.error(ret.Keyword, errs.CodeReturnFromInitializer, "Cannot return a value from an initializer")
+		}
+
+		r.resolveExpr(ret.Value)
+	}
+}
+
+// VisitClass resolves the superclass and each of the methods. Methods are
+// resolved inside of a scope defining "this", and when there is a
+// superclass another scope around that defining "super".
+func (r *R) VisitClass(c *stmt.Class) {
+	enclosingClass := r.currentClass
+	r.currentClass = class
+	defer func() {
+		r.currentClass = enclosingClass
+	}()
+
+	r.declare(c.Name)
+	r.define(c.Name)
+
+	if c.Superclass != nil {
+		if c.Superclass.Name.Lexeme == c.Name.Lexeme {
+			r.error(c.Superclass.Name, errs.CodeInheritFromSelf, "A class cannot inherit from itself")
+		}
+
+		r.currentClass = subclass
+		r.resolveExpr(c.Superclass)
+
+		r.beginScope()
+		r.peekScope()["super"] = true
+		defer r.endScope()
+	}
+
+	r.beginScope()
+	r.peekScope()["this"] = true
+
+	for _, m := range c.Methods {
+		kind := method
+		if m.Name.Lexeme == "init" {
+			kind = initializer
+		}
+
+		r.resolveFunction(m.Params, m.Body, kind)
+	}
+
+	r.endScope()
+}
+
+// VisitBinary resolves both operands.
+func (r *R) VisitBinary(b *expr.Binary) {
+	r.resolveExpr(b.Left)
+	r.resolveExpr(b.Right)
+}
+
+// VisitLiteral has nothing to resolve.
+func (r *R) VisitLiteral(*expr.Literal) {}
+
+// VisitInterpolation resolves each expression embedded in the string.
+func (r *R) VisitInterpolation(i *expr.Interpolation) {
+	for _, part := range i.Parts {
+		r.resolveExpr(part)
+	}
+}
+
+// VisitGrouping resolves the wrapped expression.
+func (r *R) VisitGrouping(g *expr.Grouping) {
+	r.resolveExpr(g.Expression)
+}
+
+// VisitUnary resolves the operand.
+func (r *R) VisitUnary(u *expr.Unary) {
+	r.resolveExpr(u.Right)
+}
+
+// VisitSequenced resolves both expressions.
+func (r *R) VisitSequenced(s *expr.Sequenced) {
+	r.resolveExpr(s.Left)
+	r.resolveExpr(s.Right)
+}
+
+// VisitTernary resolves the condition and both branches.
+func (r *R) VisitTernary(t *expr.Ternary) {
+	r.resolveExpr(t.Condition)
+	r.resolveExpr(t.Positive)
+	r.resolveExpr(t.Negative)
+}
+
+// VisitVariable ensures the variable is not being read in its own
+// initializer and then resolves the reference.
+func (r *R) VisitVariable(v *expr.Variable) {
+	if len(r.scopes) > 0 {
+		if defined, ok := r.peekScope()[v.Name.Lexeme]; ok && !defined {
+			r.error(v.Name, errs.CodeReadInInitializer, "Cannot read local variable in its own initializer")
+		}
+	}
+
+	r.resolveLocal(v, v.Name)
+}
+
+// VisitAssign resolves the assigned value and then the variable being
+// assigned to.
+func (r *R) VisitAssign(a *expr.Assign) {
+	r.resolveExpr(a.Value)
+	r.resolveLocal(a, a.Name)
+}
+
+// VisitLogical resolves both operands.
+func (r *R) VisitLogical(l *expr.Logical) {
+	r.resolveExpr(l.Left)
+	r.resolveExpr(l.Right)
+}
+
+// VisitCall resolves the callee and each of the arguments.
+func (r *R) VisitCall(c *expr.Call) {
+	r.resolveExpr(c.Callee)
+	for _, arg := range c.Arguments {
+		r.resolveExpr(arg)
+	}
+}
+
+// VisitLambda resolves the body of the anonymous function.
+func (r *R) VisitLambda(l *expr.Lambda) {
+	body := make([]stmt.Stmt, len(l.Body))
+	for i, st := range l.Body {
+		body[i] = st.(stmt.Stmt)
+	}
+
+	r.resolveFunction(l.Params, body, function)
+}
+
+// VisitGet resolves the object, properties are looked up dynamically.
+func (r *R) VisitGet(g *expr.Get) {
+	r.resolveExpr(g.Object)
+}
+
+// VisitSet resolves the value and the object, properties are looked up
+// dynamically.
+func (r *R) VisitSet(s *expr.Set) {
+	r.resolveExpr(s.Value)
+	r.resolveExpr(s.Object)
+}
+
+// VisitThis ensures the expression is within a class and resolves it like
+// any other variable.
+func (r *R) VisitThis(t *expr.This) {
+	if r.currentClass == noClass {
+		r.error(t.Keyword, errs.CodeThisOutsideClass, "Cannot use 'this' outside of a class")
+		return
+	}
+
+	r.resolveLocal(t, t.Keyword)
+}
+
+// VisitSuper ensures the expression is within a subclass and resolves it
+// like any other variable.
+func (r *R) VisitSuper(s *expr.Super) {
+	switch r.currentClass {
+	case noClass:
+		r.error(s.Keyword, errs.CodeSuperOutsideClass, "Cannot use 'super' outside of a class")
+		return
+	case class:
+		r.error(s.Keyword, errs.CodeSuperWithoutSuperclass, "Cannot use 'super' in a class with no superclass")
+		return
+	}
+
+	r.resolveLocal(s, s.Keyword)
+}
+
+// helpers
+
+func (r *R) resolveStatements(stmts []stmt.Stmt) {
+	for _, st := range stmts {
+		r.resolveStmt(st)
+	}
+}
+
+func (r *R) resolveStmt(st stmt.Stmt) {
+	st.Accept(r)
+}
+
+func (r *R) resolveExpr(e expr.Expr) {
+	e.Accept(r)
+}
+
+func (r *R) resolveFunction(params []*token.T, body []stmt.Stmt, kind functionType) {
+	enclosingFunction := r.currentFunction
+	r.currentFunction = kind
+
+	r.beginScope()
+	for _, param := range params {
+		r.declare(param)
+		r.define(param)
+	}
+	r.resolveStatements(body)
+	r.endScope()
+
+	r.currentFunction = enclosingFunction
+}
+
+// resolveLocal searches from the innermost scope outward for the name and
+// reports to the interpreter how many scopes were crossed to find it.
+func (r *R) resolveLocal(e expr.Expr, name *token.T) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if _, ok := r.scopes[i][name.Lexeme]; ok {
+			r.interp.Resolve(e, len(r.scopes)-1-i)
+			return
+		}
+	}
+}
+
+func (r *R) beginScope() {
+	r.scopes = append(r.scopes, make(map[string]bool))
+}
+
+func (r *R) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *R) peekScope() map[string]bool {
+	return r.scopes[len(r.scopes)-1]
+}
+
+func (r *R) declare(name *token.T) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	scope := r.peekScope()
+	if _, ok := scope[name.Lexeme]; ok {
+		r.error(name, errs.CodeDuplicateDeclaration, "Variable with this name already declared in this scope")
+	}
+
+	scope[name.Lexeme] = false
+}
+
+func (r *R) define(name *token.T) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	r.peekScope()[name.Lexeme] = true
+}
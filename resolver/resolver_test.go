@@ -0,0 +1,84 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/resolver"
+	"github.com/bbuck/glox/scanner"
+	"github.com/bbuck/glox/tree/expr"
+	"github.com/bbuck/glox/tree/parser"
+	"github.com/bbuck/glox/tree/stmt"
+)
+
+type depths map[expr.Expr]int
+
+func (d depths) Resolve(e expr.Expr, depth int) {
+	d[e] = depth
+}
+
+func Test_Resolve_Depths(t *testing.T) {
+	stmts := parse(t, `
+		var global = 1;
+		{
+			var a = 1;
+			{
+				a;
+				global;
+			}
+		}
+	`)
+
+	d := make(depths)
+	if err := resolver.New(d, errs.NewCollector()).Resolve(stmts); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if len(d) != 1 {
+		t.Fatalf("expected 1 resolved local but got %d", len(d))
+	}
+
+	for e, depth := range d {
+		v, ok := e.(*expr.Variable)
+		if !ok || v.Name.Lexeme != "a" || depth != 1 {
+			t.Errorf("expected 'a' resolved at depth 1 but got %v at %d", e, depth)
+		}
+	}
+}
+
+func Test_Resolve_Errors(t *testing.T) {
+	sources := []string{
+		"{ var a = a; }",
+		"{ var a = 1; var a = 2; }",
+		"return 1;",
+		"print this;",
+		"fun f() { return this; }",
+		"class A { init() { return 1; } }",
+		"class A { f() { super.f(); } }",
+		"super.f();",
+		"class A < A {}",
+	}
+
+	for _, source := range sources {
+		reporter := errs.NewCollector()
+		if err := resolver.New(make(depths), reporter).Resolve(parse(t, source)); err != resolver.ResolveError {
+			t.Errorf("%q: expected ResolveError but got %v", source, err)
+		}
+
+		if len(reporter.Diagnostics) != 1 {
+			t.Errorf("%q: expected 1 diagnostic but got %d", source, len(reporter.Diagnostics))
+		}
+	}
+}
+
+func parse(t *testing.T, source string) []stmt.Stmt {
+	s := scanner.New(source, errs.NewCollector())
+	s.ScanTokens()
+	p := parser.New(s.Tokens(), errs.NewCollector())
+	stmts := p.ParseProgram()
+	if stmts == nil {
+		t.Fatalf("%q: failed to parse", source)
+	}
+
+	return stmts
+}
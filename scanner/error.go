@@ -0,0 +1,7 @@
+package scanner
+
+import "errors"
+
+// ScanError represents a failure scanning the source, the details of which
+// have already been reported.
+var ScanError = errors.New("Scan Error")
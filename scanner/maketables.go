@@ -0,0 +1,253 @@
+//go:build ignore
+
+// maketables generates nfc_tables.go, the tables from the Unicode Character
+// Database that the scanner needs to normalize identifiers to NFC:
+//
+//	go run maketables.go [-version 17.0.0] [-ucd dir]
+//
+// The UCD files are downloaded from unicode.org for the Unicode version of
+// the Go toolchain, which the unicode package's tables also follow, unless
+// -ucd names a directory that already holds UnicodeData.txt and
+// CompositionExclusions.txt.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	version = flag.String("version", unicode.Version, "version of the Unicode Character Database")
+	ucd     = flag.String("ucd", "", "directory holding the UCD files, instead of downloading them")
+	output  = flag.String("output", "nfc_tables.go", "file to write the tables to")
+)
+
+func main() {
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("maketables: ")
+
+	classes := make(map[rune]uint8)
+	mappings := make(map[rune][]rune)
+	parse("UnicodeData.txt", func(fields []string) {
+		r := codePoint(fields[0])
+		class, err := strconv.ParseUint(fields[3], 10, 8)
+		if err != nil {
+			log.Fatalf("UnicodeData.txt: bad combining class %q", fields[3])
+		}
+
+		if class != 0 {
+			classes[r] = uint8(class)
+		}
+
+		// compatibility mappings are tagged, such as "<font> 0041", and
+		// play no part in NFC
+		if fields[5] != "" && !strings.HasPrefix(fields[5], "<") {
+			for _, field := range strings.Fields(fields[5]) {
+				mappings[r] = append(mappings[r], codePoint(field))
+			}
+		}
+	})
+
+	excluded := make(map[rune]bool)
+	parse("CompositionExclusions.txt", func(fields []string) {
+		excluded[codePoint(fields[0])] = true
+	})
+
+	decompositions := make(map[rune][]rune)
+	for r := range mappings {
+		decompositions[r] = decompose(mappings, r)
+	}
+
+	// a character is a primary composite, which NFC composes to, unless its
+	// canonical mapping is a single character, it's excluded by name or it
+	// or its mapping starts with a combining mark
+	compositions := make(map[[2]rune]rune)
+	for r, mapping := range mappings {
+		if len(mapping) == 2 && !excluded[r] && classes[r] == 0 && classes[mapping[0]] == 0 {
+			compositions[[2]rune{mapping[0], mapping[1]}] = r
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Code generated by maketables.go; DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package scanner\n\n")
+	fmt.Fprintf(buf, "// ucdVersion is the version of the Unicode Character Database the tables\n")
+	fmt.Fprintf(buf, "// were generated from.\n")
+	fmt.Fprintf(buf, "const ucdVersion = %q\n\n", *version)
+
+	fmt.Fprintf(buf, "// combiningClasses holds the canonical combining class of every character\n")
+	fmt.Fprintf(buf, "// that isn't a starter, whose class is 0.\n")
+	fmt.Fprintf(buf, "var combiningClasses = map[rune]uint8{\n")
+	var keys []rune
+	for r := range classes {
+		keys = append(keys, r)
+	}
+	sortRunes(keys)
+	writeEntries(buf, 6, len(keys), func(i int) string {
+		return fmt.Sprintf("0x%04x: %d,", keys[i], classes[keys[i]])
+	})
+	fmt.Fprintf(buf, "}\n\n")
+
+	fmt.Fprintf(buf, "// decompositions holds the full canonical decomposition of every character\n")
+	fmt.Fprintf(buf, "// that has one, other than the Hangul syllables.\n")
+	fmt.Fprintf(buf, "var decompositions = map[rune]string{\n")
+	keys = keys[:0]
+	for r := range decompositions {
+		keys = append(keys, r)
+	}
+	sortRunes(keys)
+	writeEntries(buf, 3, len(keys), func(i int) string {
+		return fmt.Sprintf("0x%04x: %s,", keys[i], quote(decompositions[keys[i]]))
+	})
+	fmt.Fprintf(buf, "}\n\n")
+
+	fmt.Fprintf(buf, "// compositions maps each pair of characters to the primary composite that\n")
+	fmt.Fprintf(buf, "// they're the canonical mapping of, other than the Hangul syllables.\n")
+	fmt.Fprintf(buf, "var compositions = map[[2]rune]rune{\n")
+	pairs := make([][2]rune, 0, len(compositions))
+	for pair := range compositions {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return compositions[pairs[i]] < compositions[pairs[j]]
+	})
+	writeEntries(buf, 3, len(pairs), func(i int) string {
+		return fmt.Sprintf("{0x%04x, 0x%04x}: 0x%04x,", pairs[i][0], pairs[i][1], compositions[pairs[i]])
+	})
+	fmt.Fprintf(buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("formatting the tables: %s", err)
+	}
+
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// decompose expands the canonical mapping of r until every character in it
+// has no mapping of its own.
+func decompose(mappings map[rune][]rune, r rune) []rune {
+	mapping, ok := mappings[r]
+	if !ok {
+		return []rune{r}
+	}
+
+	var runes []rune
+	for _, m := range mapping {
+		runes = append(runes, decompose(mappings, m)...)
+	}
+
+	return runes
+}
+
+// parse calls fn with the fields of each line of the named UCD file,
+// skipping comments and blank lines.
+func parse(name string, fn func(fields []string)) {
+	r := open(name)
+	defer r.Close()
+
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		line := lines.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		fn(fields)
+	}
+
+	if err := lines.Err(); err != nil {
+		log.Fatalf("%s: %s", name, err)
+	}
+}
+
+// open opens the named UCD file from the -ucd directory, or downloads it.
+func open(name string) io.ReadCloser {
+	if *ucd != "" {
+		f, err := os.Open(filepath.Join(*ucd, name))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return f
+	}
+
+	url := "https://www.unicode.org/Public/" + *version + "/ucd/" + name
+	resp, err := http.Get(url)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("%s: %s", url, resp.Status)
+	}
+
+	return resp.Body
+}
+
+func codePoint(field string) rune {
+	r, err := strconv.ParseUint(field, 16, 32)
+	if err != nil {
+		log.Fatalf("bad code point %q", field)
+	}
+
+	return rune(r)
+}
+
+func sortRunes(runes []rune) {
+	sort.Slice(runes, func(i, j int) bool {
+		return runes[i] < runes[j]
+	})
+}
+
+// writeEntries writes n map entries, perLine to a line.
+func writeEntries(w io.Writer, perLine, n int, entry func(i int) string) {
+	for i := 0; i < n; i += perLine {
+		var line []string
+		for j := i; j < i+perLine && j < n; j++ {
+			line = append(line, entry(j))
+		}
+		fmt.Fprintf(w, "\t%s\n", strings.Join(line, " "))
+	}
+}
+
+// quote writes the runes as a string of \u escapes, so combining marks
+// don't merge with the quotes around them.
+func quote(runes []rune) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range runes {
+		if r > 0xffff {
+			fmt.Fprintf(&sb, `\U%08x`, r)
+		} else {
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		}
+	}
+	sb.WriteByte('"')
+
+	return sb.String()
+}
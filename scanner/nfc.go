@@ -0,0 +1,123 @@
+package scanner
+
+import "unicode/utf8"
+
+//go:generate go run maketables.go
+
+// Identifiers are normalized to Unicode normalization form C, as described
+// by Unicode Standard Annex #15 (https://unicode.org/reports/tr15/), so
+// identifiers that are canonically equivalent, such as one typed with
+// combining characters and one typed with precomposed characters, are the
+// same identifier. The tables in nfc_tables.go are generated from the
+// Unicode Character Database by maketables.go.
+
+// Hangul syllables are decomposed into and composed from their jamo by
+// arithmetic rather than with the tables.
+const (
+	hangulBase  = 0xac00
+	hangulCount = 11172
+	jamoLBase   = 0x1100
+	jamoLCount  = 19
+	jamoVBase   = 0x1161
+	jamoVCount  = 21
+	jamoTBase   = 0x11a7
+	jamoTCount  = 28
+)
+
+// normalize returns s in normalization form C.
+func normalize(s string) string {
+	if isASCII(s) {
+		return s
+	}
+
+	return string(compose(decompose(s)))
+}
+
+// decompose returns the canonical decomposition of s with each run of
+// combining marks sorted into canonical order.
+func decompose(s string) []rune {
+	runes := make([]rune, 0, len(s))
+	for _, r := range s {
+		switch d, ok := decompositions[r]; {
+		case ok:
+			for _, dr := range d {
+				runes = append(runes, dr)
+			}
+		case hangulBase <= r && r < hangulBase+hangulCount:
+			i := r - hangulBase
+			runes = append(runes, jamoLBase+i/(jamoVCount*jamoTCount), jamoVBase+i%(jamoVCount*jamoTCount)/jamoTCount)
+			if t := i % jamoTCount; t != 0 {
+				runes = append(runes, jamoTBase+t)
+			}
+		default:
+			runes = append(runes, r)
+		}
+	}
+
+	// a stable insertion sort of the marks, which never move past a starter
+	// as the class of starters is 0
+	for i := 1; i < len(runes); i++ {
+		class := combiningClasses[runes[i]]
+		if class == 0 {
+			continue
+		}
+
+		for j := i; j > 0 && combiningClasses[runes[j-1]] > class; j-- {
+			runes[j], runes[j-1] = runes[j-1], runes[j]
+		}
+	}
+
+	return runes
+}
+
+// compose replaces each character that can be combined with the last
+// starter before it, and isn't blocked from it by a character of the same
+// or a higher combining class, with their primary composite. The runes are
+// composed in place.
+func compose(runes []rune) []rune {
+	composed := runes[:0]
+	starter := -1
+	var lastClass uint8
+	for _, r := range runes {
+		class := combiningClasses[r]
+		if starter >= 0 {
+			adjacent := starter == len(composed)-1
+			if adjacent || (lastClass != 0 && lastClass < class) {
+				if c, ok := composePair(composed[starter], r); ok {
+					composed[starter] = c
+					continue
+				}
+			}
+		}
+
+		if class == 0 {
+			starter = len(composed)
+		}
+		lastClass = class
+		composed = append(composed, r)
+	}
+
+	return composed
+}
+
+func composePair(a, b rune) (rune, bool) {
+	switch {
+	case jamoLBase <= a && a < jamoLBase+jamoLCount && jamoVBase <= b && b < jamoVBase+jamoVCount:
+		return hangulBase + ((a-jamoLBase)*jamoVCount+b-jamoVBase)*jamoTCount, true
+	case hangulBase <= a && a < hangulBase+hangulCount && (a-hangulBase)%jamoTCount == 0 && jamoTBase < b && b < jamoTBase+jamoTCount:
+		return a + b - jamoTBase, true
+	}
+
+	c, ok := compositions[[2]rune{a, b}]
+	return c, ok
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
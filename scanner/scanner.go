@@ -1,39 +1,91 @@
 package scanner
 
 import (
-	"bytes"
-
+	"bufio"
+	"io"
 	"strconv"
-
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/bbuck/glox/errs"
 	"github.com/bbuck/glox/token"
 )
 
 // S is a token scanner for the Lox programming language. It will scan
-// the source for tokens and build a list of tokens.
+// the source for tokens and either build a list of tokens, with ScanTokens,
+// or hand them out one at a time as they're scanned, with Next.
 type S struct {
-	Source    string
-	runes     []rune
+	Source string
+
+	// Lossless, if set before scanning, keeps the source text and the
+	// whitespace and comments around every token so the source can be
+	// rebuilt from the tokens with token.Source.
+	Lossless bool
+
+	reporter  errs.Reporter
+	reader    *bufio.Reader
+	readErr   error
 	tokens    []*token.T
+	pending   []*token.T
+	eof       *token.T
 	completed bool
 	hadError  bool
 
+	// runes read from the source but not yet consumed, and the bytes each
+	// was decoded from
+	lookahead []rune
+	raws      []string
+
+	// the runes consumed for the token currently being scanned, and the
+	// source bytes they were decoded from, which differ where the source
+	// isn't valid UTF-8
+	lexeme []rune
+	text   []byte
+
+	// trivia waiting for the next token, and whether the last call to
+	// scanToken found any
+	leading   []token.Trivia
+	sawTrivia bool
+
+	// brace depth within each string interpolation currently open, the
+	// innermost last
+	interpolations []int
+
 	// locate ourselves
-	start   int
-	current int
-	line    uint
+	line     uint
+	startPos token.Position
+	pos      token.Position
 }
 
 // New constructs a new scanner for the provided source string
-// with an empty list of tokens.
-func New(source string) *S {
+// with an empty list of tokens. Any errors found while scanning are
+// reported to the reporter.
+func New(source string, reporter errs.Reporter) *S {
+	s := NewReader(strings.NewReader(source), reporter)
+	s.Source = source
+
+	return s
+}
+
+// NewReader constructs a new scanner that reads the source from r as
+// tokens are requested, so the whole source never needs to be held in
+// memory. Source will be empty for scanners created this way.
+func NewReader(r io.Reader, reporter errs.Reporter) *S {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
 	return &S{
-		Source: source,
-		runes:  []rune(source),
-		tokens: make([]*token.T, 0),
-		line:   1,
+		reporter: reporter,
+		reader:   br,
+		tokens:   make([]*token.T, 0),
+		line:     1,
+		pos: token.Position{
+			Line:   1,
+			Column: 1,
+		},
 	}
 }
 
@@ -46,14 +98,64 @@ func (s *S) ScanTokens() bool {
 		return s.hadError
 	}
 
-	for !s.isAtEnd() {
-		s.start = s.current
+	for {
+		tok := s.Next()
+		s.tokens = append(s.tokens, tok)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	s.completed = true
+
+	return s.hadError
+}
+
+// Next scans and returns the next token from the source. Once the end of
+// the source has been reached every call returns the EOF token. Tokens
+// handed out by Next are not added to the list returned by Tokens, so Next
+// should not be mixed with ScanTokens.
+func (s *S) Next() *token.T {
+	if s.eof != nil {
+		return s.eof
+	}
+
+	for len(s.pending) == 0 {
+		s.lexeme = s.lexeme[:0]
+		s.text = s.text[:0]
+		s.startPos = s.pos
+		s.sawTrivia = false
+
+		if s.isAtEnd() {
+			if len(s.interpolations) > 0 {
+				s.interpolations = nil
+				s.error(errs.CodeUnterminatedString, "Unterminated string interpolation.")
+			}
+
+			s.addTokenRaw(token.EOF, "", nil)
+			break
+		}
+
 		s.scanToken()
+		if !s.Lossless {
+			continue
+		}
+
+		if len(s.pending) > 0 {
+			s.scanTrailingTrivia(s.pending[0])
+		} else if !s.sawTrivia {
+			// whatever was scanned couldn't be made into a token, most
+			// likely because of an error, but still has to be kept
+			s.appendTrivia(&s.leading, token.SkippedTrivia)
+		}
 	}
 
-	s.addTokenRaw(token.EOF, "", nil)
+	tok := s.pending[0]
+	s.pending = s.pending[1:]
+	if tok.Type == token.EOF {
+		s.eof = tok
+	}
 
-	return s.hadError
+	return tok
 }
 
 // Tokens returns the list of scanned tokens after ScanTokens has been called.
@@ -63,9 +165,85 @@ func (s *S) Tokens() []*token.T {
 	return s.tokens
 }
 
+// HadError returns true if an error has been reported for any of the tokens
+// scanned so far.
+func (s *S) HadError() bool {
+	return s.hadError
+}
+
+// Err returns the error, if any, that stopped the scanner from reading the
+// rest of the source. Reaching the end of the source is not an error.
+func (s *S) Err() error {
+	return s.readErr
+}
+
 func (s *S) addTokenRaw(t token.Type, lex string, lit interface{}) {
 	tok := token.New(t, lex, lit, s.line)
-	s.tokens = append(s.tokens, tok)
+	tok.Start = s.startPos
+	tok.End = s.pos
+	if s.Lossless {
+		tok.Text = s.currentText()
+		tok.Leading = s.leading
+		s.leading = nil
+	}
+	s.pending = append(s.pending, tok)
+}
+
+// trivia records that the text just scanned is trivia of the given kind,
+// keeping it to lead the next token in lossless mode.
+func (s *S) trivia(kind token.TriviaKind) {
+	s.sawTrivia = true
+	if s.Lossless {
+		s.appendTrivia(&s.leading, kind)
+	}
+}
+
+// appendTrivia adds the current lexeme to the list as trivia of the given
+// kind, merging runs of whitespace or newlines into one piece.
+func (s *S) appendTrivia(list *[]token.Trivia, kind token.TriviaKind) {
+	text := s.currentText()
+	if n := len(*list); n > 0 && (kind == token.WhitespaceTrivia || kind == token.NewlineTrivia) && (*list)[n-1].Kind == kind {
+		(*list)[n-1].Text += text
+		return
+	}
+
+	*list = append(*list, token.Trivia{
+		Kind: kind,
+		Text: text,
+	})
+}
+
+// scanTrailingTrivia attaches the whitespace and comments that follow tok
+// on the same line to it. A block comment that starts on the line is
+// trailing trivia even if it ends on another.
+func (s *S) scanTrailingTrivia(tok *token.T) {
+	for {
+		s.lexeme = s.lexeme[:0]
+		s.text = s.text[:0]
+		s.startPos = s.pos
+
+		switch r := s.peek(); {
+		case r == ' ' || r == '\t' || r == '\r':
+			s.advance()
+			s.appendTrivia(&tok.Trailing, token.WhitespaceTrivia)
+		case r == '/' && s.peekNext() == '/':
+			s.scanLineComment()
+			s.appendTrivia(&tok.Trailing, token.LineCommentTrivia)
+		case r == '/' && s.peekNext() == '*':
+			line := s.line
+			// consume /
+			s.advance()
+			// consume *
+			s.advance()
+			s.scanBlockComment()
+			s.appendTrivia(&tok.Trailing, token.BlockCommentTrivia)
+			if s.line != line {
+				return
+			}
+		default:
+			return
+		}
+	}
 }
 
 func (s *S) addToken(t token.Type, lit interface{}) {
@@ -78,7 +256,36 @@ func (s *S) addNoValueToken(t token.Type) {
 }
 
 func (s *S) isAtEnd() bool {
-	return s.current >= len(s.runes)
+	return !s.fill(1)
+}
+
+// fill reads from the source until there are at least n runes of lookahead,
+// returning false if the source ends first.
+func (s *S) fill(n int) bool {
+	for len(s.lookahead) < n {
+		if s.reader == nil {
+			return false
+		}
+
+		// decode the rune ourselves, rather than with ReadRune, to keep
+		// the bytes of invalid UTF-8 that would become utf8.RuneError
+		buf, err := s.reader.Peek(utf8.UTFMax)
+		if len(buf) == 0 {
+			if err != io.EOF {
+				s.readErr = err
+			}
+			s.reader = nil
+
+			return false
+		}
+
+		r, size := utf8.DecodeRune(buf)
+		s.lookahead = append(s.lookahead, r)
+		s.raws = append(s.raws, string(buf[:size]))
+		s.reader.Discard(size)
+	}
+
+	return true
 }
 
 func (s *S) scanToken() {
@@ -89,8 +296,21 @@ func (s *S) scanToken() {
 	case ')':
 		s.addNoValueToken(token.RightParen)
 	case '{':
+		if n := len(s.interpolations); n > 0 {
+			s.interpolations[n-1]++
+		}
 		s.addNoValueToken(token.LeftBrace)
 	case '}':
+		if n := len(s.interpolations); n > 0 {
+			if s.interpolations[n-1] == 0 {
+				// this brace closes the interpolated expression, so pick
+				// the string back up where it left off
+				s.interpolations = s.interpolations[:n-1]
+				s.scanString()
+				return
+			}
+			s.interpolations[n-1]--
+		}
 		s.addNoValueToken(token.RightBrace)
 	case ',':
 		s.addNoValueToken(token.Comma)
@@ -104,6 +324,8 @@ func (s *S) scanToken() {
 		s.addNoValueToken(token.Semicolon)
 	case '*':
 		s.addNoValueToken(token.Star)
+	case '%':
+		s.addNoValueToken(token.Percent)
 	case '?':
 		s.addNoValueToken(token.QuestionMark)
 	case ':':
@@ -117,33 +339,29 @@ func (s *S) scanToken() {
 	case '<':
 		s.scanEqualToken(token.LessEqual, token.Less)
 	case '/':
-		if s.match('/') {
-			for s.peek() != '\n' && !s.isAtEnd() {
-				s.advance()
-			}
+		if s.peek() == '/' {
+			s.scanLineComment()
+			s.trivia(token.LineCommentTrivia)
 		} else if s.match('*') {
 			s.scanBlockComment()
+			s.trivia(token.BlockCommentTrivia)
 		} else {
 			s.addNoValueToken(token.Slash)
 		}
 	case '"':
 		s.scanString()
-	case ' ':
-		fallthrough
-	case '\r':
-		fallthrough
-	case '\t':
-		// ignore these tokens
+	case ' ', '\r', '\t':
+		s.trivia(token.WhitespaceTrivia)
 	case '\n':
 		s.line++
+		s.trivia(token.NewlineTrivia)
 	default:
 		if isDigit(r) {
-			s.scanNumber()
-		} else if isAlpha(r) {
+			s.scanNumber(r)
+		} else if isIdentifierStart(r) {
 			s.scanIdentifier()
 		} else {
-			errs.Error(s.line, "Unexpected character.")
-			s.hadError = true
+			s.unexpectedCharacter(r)
 		}
 	}
 }
@@ -157,25 +375,41 @@ func (s *S) scanEqualToken(found token.Type, notFound token.Type) {
 }
 
 func (s *S) advance() rune {
-	s.current++
+	s.fill(1)
+	r, raw := s.lookahead[0], s.raws[0]
+	s.lookahead = s.lookahead[1:]
+	s.raws = s.raws[1:]
+	s.lexeme = append(s.lexeme, r)
+	s.text = append(s.text, raw...)
+
+	// use the bytes that were read rather than utf8.RuneLen so invalid
+	// bytes, which become a single utf8.RuneError each, are counted
+	// correctly
+	s.pos.Offset += len(raw)
+	if r == '\n' {
+		s.pos.Line++
+		s.pos.Column = 1
+	} else {
+		s.pos.Column++
+	}
 
-	return s.runes[s.current-1]
+	return r
 }
 
 func (s *S) peek() rune {
-	if s.isAtEnd() {
+	if !s.fill(1) {
 		return rune(0)
 	}
 
-	return s.runes[s.current]
+	return s.lookahead[0]
 }
 
 func (s *S) peekNext() rune {
-	if s.current+1 >= len(s.runes) {
+	if !s.fill(2) {
 		return rune(0)
 	}
 
-	return s.runes[s.current+1]
+	return s.lookahead[1]
 }
 
 func (s *S) match(expected rune) bool {
@@ -183,74 +417,329 @@ func (s *S) match(expected rune) bool {
 		return false
 	}
 
-	if s.runes[s.current] != expected {
+	if s.lookahead[0] != expected {
 		return false
 	}
 
-	s.current++
+	s.advance()
 	return true
 }
 
+// scanString scans the characters of a string up to its closing quote,
+// decoding escape sequences as it goes. A string containing `${` is split
+// at each interpolated expression: the text before it becomes an
+// Interpolation token, the expression is scanned as normal tokens and the
+// brace closing it resumes the string. The final part of the string is
+// always a String token.
 func (s *S) scanString() {
-	for s.peek() != '"' && !s.isAtEnd() {
-		if s.peek() == '\n' {
-			s.line++
+	value := new(strings.Builder)
+	for {
+		switch {
+		case s.isAtEnd():
+			s.error(errs.CodeUnterminatedString, "Unterminated string.")
+			return
+		case s.peek() == '"':
+			// capture the closing quote
+			s.advance()
+			s.addToken(token.String, value.String())
+			return
+		case s.peek() == '$' && s.peekNext() == '{':
+			// consume $
+			s.advance()
+			// consume {
+			s.advance()
+			s.interpolations = append(s.interpolations, 0)
+			s.addToken(token.Interpolation, value.String())
+			return
+		case s.peek() == '\\':
+			s.scanEscape(value)
+		default:
+			if s.peek() == '\n' {
+				s.line++
+			}
+
+			value.WriteRune(s.advance())
 		}
-		s.advance()
 	}
+}
 
+// scanEscape decodes the escape sequence starting at the next backslash
+// into value. Supported escapes are \n, \t, \r, \\, \", \$ and \u{XXXX}
+// where XXXX is between one and six hex digits naming a Unicode code point.
+func (s *S) scanEscape(value *strings.Builder) {
+	start := s.pos
+	// consume \
+	s.advance()
 	if s.isAtEnd() {
-		errs.Error(s.line, "Unterminated string.")
-		s.hadError = true
+		// leave it to the string to report being unterminated
 		return
 	}
 
-	// capture the closing quote
-	s.advance()
+	r := s.advance()
+	switch r {
+	case 'n':
+		value.WriteRune('\n')
+	case 't':
+		value.WriteRune('\t')
+	case 'r':
+		value.WriteRune('\r')
+	case '\\', '"', '$':
+		value.WriteRune(r)
+	case 'u':
+		s.scanUnicodeEscape(value, start)
+	default:
+		if r == '\n' {
+			s.line++
+		}
 
-	value := s.currentLexeme()
-	s.addToken(token.String, value[1:len(value)-1])
+		s.errorAt(start, errs.CodeInvalidEscape, "Invalid escape sequence.")
+	}
 }
 
-func (s *S) scanNumber() {
-	for isDigit(s.peek()) {
-		s.advance()
+// scanUnicodeEscape decodes the `{XXXX}` following a \u escape.
+func (s *S) scanUnicodeEscape(value *strings.Builder, start token.Position) {
+	if !s.match('{') {
+		s.errorAt(start, errs.CodeInvalidEscape, "Expect '{' after '\\u'.")
+		return
+	}
+
+	digits := 0
+	code := rune(0)
+	for isHexDigit(s.peek()) {
+		code = code<<4 | hexValue(s.advance())
+		digits++
+	}
+
+	if !s.match('}') {
+		s.errorAt(start, errs.CodeInvalidEscape, "Expect '}' after unicode escape.")
+		return
+	}
+
+	if digits == 0 || digits > 6 || !utf8.ValidRune(code) {
+		s.errorAt(start, errs.CodeInvalidEscape, "Invalid unicode code point in escape.")
+		return
+	}
+
+	value.WriteRune(code)
+}
+
+// scanNumber scans a number literal whose first digit has been consumed.
+// Numbers are decimal, with an optional fraction and exponent, unless they
+// begin with one of the prefixes 0x, 0b or 0o for hexadecimal, binary and
+// octal integers. Single underscores may separate any digits to group
+// them, like 1_000_000. Numbers without a fraction or exponent are
+// integers, and their literal value is an int64 rather than a float64.
+func (s *S) scanNumber(first rune) {
+	if first == '0' {
+		switch s.peek() {
+		case 'x', 'X':
+			s.scanRadixNumber(16, "hexadecimal")
+			return
+		case 'b', 'B':
+			s.scanRadixNumber(2, "binary")
+			return
+		case 'o', 'O':
+			s.scanRadixNumber(8, "octal")
+			return
+		}
+	}
+
+	digits := new(strings.Builder)
+	digits.WriteRune(first)
+	if !s.scanDigits(digits, 10) {
+		return
 	}
 
+	integer := true
 	if s.peek() == '.' && isDigit(s.peekNext()) {
-		s.advance()
+		integer = false
+		digits.WriteRune(s.advance())
+		if !s.scanDigits(digits, 10) {
+			return
+		}
+	}
 
-		for isDigit(s.peek()) {
-			s.advance()
+	if s.peek() == 'e' || s.peek() == 'E' {
+		integer = false
+		start := s.pos
+		digits.WriteRune(s.advance())
+		if s.peek() == '+' || s.peek() == '-' {
+			digits.WriteRune(s.advance())
+		}
+
+		if !isDigitIn(s.peek(), 10) {
+			s.errorAt(start, errs.CodeMalformedNumber, "Expect digits in exponent.")
+			s.skipNumber()
+			return
+		}
+
+		if !s.scanDigits(digits, 10) {
+			return
 		}
 	}
 
-	value := s.currentLexeme()
-	f64, _ := strconv.ParseFloat(value, 64)
+	if integer {
+		s.addInteger(digits.String(), 10)
+		return
+	}
+
+	f64, err := strconv.ParseFloat(digits.String(), 64)
+	if err != nil {
+		s.numberError(err)
+		return
+	}
+
 	s.addToken(token.Number, f64)
 }
 
+// scanRadixNumber scans an integer written in the given base after the
+// leading 0, name describes the base in error messages.
+func (s *S) scanRadixNumber(base int, name string) {
+	// consume the x, b or o
+	s.advance()
+
+	if !isDigitIn(s.peek(), base) {
+		if isIdentifierContinue(s.peek()) {
+			s.invalidDigit(name)
+		} else {
+			s.error(errs.CodeMalformedNumber, "Expect digits after '"+s.currentLexeme()+"'.")
+		}
+		return
+	}
+
+	digits := new(strings.Builder)
+	if !s.scanDigits(digits, base) {
+		return
+	}
+
+	if isIdentifierContinue(s.peek()) {
+		s.invalidDigit(name)
+		return
+	}
+
+	s.addInteger(digits.String(), base)
+}
+
+// addInteger adds a Number token for the integer with the given digits.
+func (s *S) addInteger(digits string, base int) {
+	i64, err := strconv.ParseInt(digits, base, 64)
+	if err != nil {
+		s.numberError(err)
+		return
+	}
+
+	s.addToken(token.Number, i64)
+}
+
+// scanDigits consumes digits in the given base, and the underscores
+// separating them, writing the digits to buf. It returns false if an
+// underscore was misplaced, having reported it and skipped the rest of the
+// number.
+func (s *S) scanDigits(buf *strings.Builder, base int) bool {
+	for {
+		switch r := s.peek(); {
+		case isDigitIn(r, base):
+			buf.WriteRune(s.advance())
+		case r == '_':
+			start := s.pos
+			s.advance()
+			if !isDigitIn(s.peek(), base) {
+				s.errorAt(start, errs.CodeMalformedNumber, "Expect digit after '_' in number.")
+				s.skipNumber()
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
+// invalidDigit reports the next character as not being a digit in a number
+// of the named base.
+func (s *S) invalidDigit(name string) {
+	start := s.pos
+	r := s.advance()
+	s.errorAt(start, errs.CodeMalformedNumber, "Invalid digit '"+string(r)+"' in "+name+" number.")
+	s.skipNumber()
+}
+
+// numberError reports the error from parsing a number's digits.
+func (s *S) numberError(err error) {
+	if nerr, ok := err.(*strconv.NumError); ok && nerr.Err == strconv.ErrRange {
+		s.error(errs.CodeMalformedNumber, "Number is out of range.")
+		return
+	}
+
+	s.error(errs.CodeMalformedNumber, "Invalid number.")
+}
+
+// skipNumber consumes what's left of a malformed number so it isn't
+// scanned as further tokens.
+func (s *S) skipNumber() {
+	for isIdentifierContinue(s.peek()) {
+		s.advance()
+	}
+}
+
 func (s *S) scanIdentifier() {
-	for isIdentifierRune(s.peek()) {
+	for isIdentifierContinue(s.peek()) {
 		s.advance()
 	}
 
-	lexeme := s.currentLexeme()
+	lexeme := normalize(s.currentLexeme())
 	if kind, ok := keywords[lexeme]; ok {
 		s.addTokenRaw(kind, lexeme, nil)
 		return
 	}
 
+	if scripts := mixedScripts(lexeme); scripts != nil {
+		msg := "Identifier mixes " + joinNames(scripts) + " characters."
+		s.reporter.Report(errs.NewWarning(errs.CodeMixedScripts, token.Span{Start: s.startPos, End: s.pos}, msg).
+			WithNote("characters from different scripts can look identical, check every character is the one intended"))
+	}
+
 	s.addTokenRaw(token.Identifier, lexeme, nil)
 }
 
+// unexpectedCharacter reports a character that can't start any token,
+// suggesting the symbol it was probably meant to be if it's a lookalike.
+func (s *S) unexpectedCharacter(r rune) {
+	span := token.Span{Start: s.startPos, End: s.pos}
+	msg := "Unexpected character " + describeRune(r) + "."
+
+	var d *errs.Diagnostic
+	switch {
+	case confusables[r] != "":
+		d = errs.NewError(errs.CodeConfusableCharacter, span, msg).
+			WithNote("this looks like '" + confusables[r] + "' but is a different character")
+	case unicode.IsDigit(r):
+		d = errs.NewError(errs.CodeUnexpectedCharacter, span, msg).
+			WithNote("numbers can only be written with the ASCII digits 0-9")
+	case r >= utf8.RuneSelf && !unicode.IsGraphic(r):
+		d = errs.NewError(errs.CodeConfusableCharacter, span, msg).
+			WithNote("this character is invisible")
+	default:
+		d = errs.NewError(errs.CodeUnexpectedCharacter, span, msg)
+	}
+
+	s.reporter.Report(d)
+	s.hadError = true
+}
+
+// scanLineComment consumes a comment up to, but not including, the end of
+// the line.
+func (s *S) scanLineComment() {
+	for s.peek() != '\n' && !s.isAtEnd() {
+		s.advance()
+	}
+}
+
 func (s *S) scanBlockComment() {
 	level := 1
 	for level > 0 {
 		switch {
 		case s.isAtEnd():
-			errs.Error(s.line, "Unterminated block comment.")
-			s.hadError = true
+			s.error(errs.CodeUnterminatedComment, "Unterminated block comment.")
 			return
 		case s.peek() == '*' && s.peekNext() == '/':
 			level--
@@ -274,25 +763,63 @@ func (s *S) scanBlockComment() {
 	}
 }
 
+// error reports a diagnostic covering the current lexeme.
+func (s *S) error(code, msg string) {
+	s.reporter.Report(errs.NewError(code, token.Span{Start: s.startPos, End: s.pos}, msg))
+	s.hadError = true
+}
+
+// errorAt reports a diagnostic covering just the source from start up to
+// the current position, for errors in part of a token.
+func (s *S) errorAt(start token.Position, code, msg string) {
+	s.reporter.Report(errs.NewError(code, token.Span{Start: start, End: s.pos}, msg))
+	s.hadError = true
+}
+
 func (s *S) currentLexeme() string {
-	buf := new(bytes.Buffer)
-	for i := s.start; i < s.current; i++ {
-		buf.WriteRune(s.runes[i])
-	}
+	return string(s.lexeme)
+}
 
-	return buf.String()
+// currentText returns the source of the current lexeme byte for byte.
+func (s *S) currentText() string {
+	return string(s.text)
 }
 
 // unattched helpers
 
-func isAlpha(r rune) bool {
-	return unicode.IsLetter(r)
+// isDigit returns true for the ASCII digits only, other scripts' digits
+// are never part of a number.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
 }
 
-func isDigit(r rune) bool {
-	return unicode.IsNumber(r)
+// isDigitIn returns true if r is a digit in the given base, which is one of
+// 2, 8, 10 or 16.
+func isDigitIn(r rune, base int) bool {
+	switch base {
+	case 2:
+		return r == '0' || r == '1'
+	case 8:
+		return r >= '0' && r <= '7'
+	case 16:
+		return isHexDigit(r)
+	default:
+		return r >= '0' && r <= '9'
+	}
+}
+
+func isHexDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
 }
 
-func isIdentifierRune(r rune) bool {
-	return isDigit(r) || isAlpha(r) || r == '_'
+// hexValue returns the value of a rune for which isHexDigit is true.
+func hexValue(r rune) rune {
+	switch {
+	case r >= 'a':
+		return r - 'a' + 10
+	case r >= 'A':
+		return r - 'A' + 10
+	default:
+		return r - '0'
+	}
 }
@@ -0,0 +1,404 @@
+package scanner_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/scanner"
+	"github.com/bbuck/glox/token"
+)
+
+func Test_ScanTokens_Positions(t *testing.T) {
+	s := scanner.New("var é = \"a\nb\";\n  x", errs.NewCollector())
+	s.ScanTokens()
+
+	expected := []token.Span{
+		span(1, 1, 0, 1, 4, 3),   // var
+		span(1, 5, 4, 1, 6, 6),   // é, two bytes
+		span(1, 7, 7, 1, 8, 8),   // =
+		span(1, 9, 9, 2, 3, 14),  // "a\nb"
+		span(2, 3, 14, 2, 4, 15), // ;
+		span(3, 3, 18, 3, 4, 19), // x
+		span(3, 4, 19, 3, 4, 19), // EOF
+	}
+
+	toks := s.Tokens()
+	if len(toks) != len(expected) {
+		t.Fatalf("expected %d tokens but got %d", len(expected), len(toks))
+	}
+
+	for i, tok := range toks {
+		if tok.Span() != expected[i] {
+			t.Errorf("token %d (%s): expected span %+v but got %+v", i, tok.Type, expected[i], tok.Span())
+		}
+	}
+}
+
+func Test_Next_MatchesScanTokens(t *testing.T) {
+	source := "var a = \"str\"; // comment\n/* block */ print a + 1.5;"
+	s := scanner.New(source, errs.NewCollector())
+	s.ScanTokens()
+	expected := s.Tokens()
+
+	r := scanner.NewReader(strings.NewReader(source), errs.NewCollector())
+	for i, want := range expected {
+		got := r.Next()
+		if got.Type != want.Type || got.Lexeme != want.Lexeme || got.Literal != want.Literal || got.Span() != want.Span() {
+			t.Errorf("token %d: expected %s at %s but got %s at %s", i, want, want.Span(), got, got.Span())
+		}
+	}
+
+	if tok := r.Next(); tok.Type != token.EOF {
+		t.Errorf("expected EOF to repeat after the end but got %s", tok)
+	}
+}
+
+func Test_ScanTokens_Escapes(t *testing.T) {
+	tests := map[string]string{
+		`"a\nb"`:            "a\nb",
+		`"\ttab"`:           "\ttab",
+		`"back\\slash"`:     "back\\slash",
+		`"\"quoted\""`:      `"quoted"`,
+		`"\${literal}"`:     "${literal}",
+		`"\u{e9}\u{1F600}"`: "\u00e9\U0001F600",
+	}
+
+	for source, expected := range tests {
+		reporter := errs.NewCollector()
+		s := scanner.New(source, reporter)
+		s.ScanTokens()
+		if reporter.HasErrors() {
+			t.Errorf("%s: unexpected errors %v", source, reporter.Diagnostics)
+			continue
+		}
+
+		tok := s.Tokens()[0]
+		if tok.Type != token.String || tok.Literal != expected {
+			t.Errorf("%s: expected string %q but got %s %q", source, expected, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func Test_ScanTokens_InvalidEscapes(t *testing.T) {
+	tests := map[string]token.Span{
+		`"a\qb"`:        span(1, 3, 2, 1, 5, 4),
+		`"\u41"`:        span(1, 2, 1, 1, 4, 3),
+		`"\u{}"`:        span(1, 2, 1, 1, 6, 5),
+		`"\u{110000}"`:  span(1, 2, 1, 1, 12, 11),
+		`"\u{D800}"`:    span(1, 2, 1, 1, 10, 9),
+		`"\u{1234567}"`: span(1, 2, 1, 1, 13, 12),
+		`"\u{41"`:       span(1, 2, 1, 1, 7, 6),
+	}
+
+	for source, expected := range tests {
+		reporter := errs.NewCollector()
+		scanner.New(source, reporter).ScanTokens()
+		if len(reporter.Diagnostics) != 1 {
+			t.Errorf("%s: expected one diagnostic but got %v", source, reporter.Diagnostics)
+			continue
+		}
+
+		d := reporter.Diagnostics[0]
+		if d.Code != errs.CodeInvalidEscape || d.Span != expected {
+			t.Errorf("%s: expected %s at %s but got %s at %s", source, errs.CodeInvalidEscape, expected, d.Code, d.Span)
+		}
+	}
+}
+
+func Test_ScanTokens_Interpolation(t *testing.T) {
+	s := scanner.New(`"a${b + "c${d}"}e${ {} }"`, errs.NewCollector())
+	s.ScanTokens()
+
+	expected := []struct {
+		typ     token.Type
+		literal interface{}
+	}{
+		{token.Interpolation, "a"},
+		{token.Identifier, nil},
+		{token.Plus, nil},
+		{token.Interpolation, "c"},
+		{token.Identifier, nil},
+		{token.String, ""},
+		{token.Interpolation, "e"},
+		{token.LeftBrace, nil},
+		{token.RightBrace, nil},
+		{token.String, ""},
+		{token.EOF, nil},
+	}
+
+	toks := s.Tokens()
+	if len(toks) != len(expected) {
+		t.Fatalf("expected %d tokens but got %v", len(expected), toks)
+	}
+
+	for i, tok := range toks {
+		if tok.Type != expected[i].typ || tok.Literal != expected[i].literal {
+			t.Errorf("token %d: expected %s %v but got %s %v", i, expected[i].typ, expected[i].literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func Test_ScanTokens_UnterminatedInterpolation(t *testing.T) {
+	reporter := errs.NewCollector()
+	scanner.New(`"a${b`, reporter).ScanTokens()
+	if len(reporter.Diagnostics) != 1 || reporter.Diagnostics[0].Code != errs.CodeUnterminatedString {
+		t.Errorf("expected an unterminated string diagnostic but got %v", reporter.Diagnostics)
+	}
+}
+
+func Test_ScanTokens_Numbers(t *testing.T) {
+	tests := map[string]interface{}{
+		"0":                     int64(0),
+		"42":                    int64(42),
+		"3.25":                  3.25,
+		"1_000_000":             int64(1000000),
+		"1_0.2_5":               10.25,
+		"1e3":                   1000.0,
+		"1.5e-3":                0.0015,
+		"2E+2":                  200.0,
+		"1_0e1_0":               1e11,
+		"0xFF":                  int64(255),
+		"0Xdead_BEEF":           int64(0xdeadbeef),
+		"0b1010":                int64(10),
+		"0B1111_0000":           int64(240),
+		"0o17":                  int64(15),
+		"0O7_7":                 int64(63),
+		"0x7FFF_FFFF_FFFF_FFFF": int64(9223372036854775807),
+	}
+
+	for source, expected := range tests {
+		reporter := errs.NewCollector()
+		s := scanner.New(source, reporter)
+		s.ScanTokens()
+		if reporter.HasErrors() {
+			t.Errorf("%s: unexpected errors %v", source, reporter.Diagnostics)
+			continue
+		}
+
+		toks := s.Tokens()
+		if len(toks) != 2 || toks[0].Type != token.Number || toks[0].Literal != expected {
+			t.Errorf("%s: expected number %v but got %v", source, expected, toks)
+		}
+	}
+}
+
+func Test_ScanTokens_MalformedNumbers(t *testing.T) {
+	tests := map[string]struct {
+		message string
+		span    token.Span
+	}{
+		"0x":                    {"Expect digits after '0x'.", span(1, 1, 0, 1, 3, 2)},
+		"0b;":                   {"Expect digits after '0b'.", span(1, 1, 0, 1, 3, 2)},
+		"0b102":                 {"Invalid digit '2' in binary number.", span(1, 5, 4, 1, 6, 5)},
+		"0o8":                   {"Invalid digit '8' in octal number.", span(1, 3, 2, 1, 4, 3)},
+		"0xFG":                  {"Invalid digit 'G' in hexadecimal number.", span(1, 4, 3, 1, 5, 4)},
+		"1e":                    {"Expect digits in exponent.", span(1, 2, 1, 1, 3, 2)},
+		"2.5e+":                 {"Expect digits in exponent.", span(1, 4, 3, 1, 6, 5)},
+		"1__000":                {"Expect digit after '_' in number.", span(1, 2, 1, 1, 3, 2)},
+		"1_":                    {"Expect digit after '_' in number.", span(1, 2, 1, 1, 3, 2)},
+		"0x_1":                  {"Invalid digit '_' in hexadecimal number.", span(1, 3, 2, 1, 4, 3)},
+		"1e999":                 {"Number is out of range.", span(1, 1, 0, 1, 6, 5)},
+		"0x8000_0000_0000_0000": {"Number is out of range.", span(1, 1, 0, 1, 22, 21)},
+		"9223372036854775808":   {"Number is out of range.", span(1, 1, 0, 1, 20, 19)},
+	}
+
+	for source, expected := range tests {
+		reporter := errs.NewCollector()
+		s := scanner.New(source, reporter)
+		s.ScanTokens()
+		if len(reporter.Diagnostics) != 1 {
+			t.Errorf("%s: expected one diagnostic but got %v", source, reporter.Diagnostics)
+			continue
+		}
+
+		d := reporter.Diagnostics[0]
+		if d.Code != errs.CodeMalformedNumber || d.Message != expected.message || d.Span != expected.span {
+			t.Errorf("%s: expected %q at %s but got %s %q at %s", source, expected.message, expected.span, d.Code, d.Message, d.Span)
+		}
+
+		for _, tok := range s.Tokens() {
+			if tok.Type == token.Number || tok.Type == token.Identifier {
+				t.Errorf("%s: expected the malformed number to be skipped but got %s", source, tok)
+			}
+		}
+	}
+}
+
+func Test_ScanTokens_Identifiers(t *testing.T) {
+	tests := map[string]string{
+		"_private":           "_private",
+		"camelCase2":         "camelCase2",
+		"π":                  "π",
+		"名前":                 "名前",
+		"naïve":              "naïve",
+		"nai\u0308ve":        "naïve", // combining diaeresis is normalized
+		"a\u0302\u0301":      "ấ",     // both marks are composed
+		"a\u0302\u0323":      "ậ",     // marks are put in canonical order
+		"a\u0323\u0302":      "ậ",
+		"\u212b":             "Å",            // angstrom sign is a singleton for Å
+		"\u0958":             "\u0915\u093c", // excluded from composition
+		"\u1100\u1161\u11a8": "각",            // hangul jamo
+		"größe":              "größe",
+		"ﾊﾟ":                 "ﾊﾟ",
+		"Latin名前カタカナ":        "Latin名前カタカナ",
+	}
+
+	for source, expected := range tests {
+		reporter := errs.NewCollector()
+		s := scanner.New(source, reporter)
+		s.ScanTokens()
+
+		tok := s.Tokens()[0]
+		if tok.Type != token.Identifier || tok.Lexeme != expected {
+			t.Errorf("%q: expected identifier %q but got %s %q", source, expected, tok.Type, tok.Lexeme)
+		}
+
+		if len(reporter.Diagnostics) > 0 {
+			t.Errorf("%q: unexpected diagnostics %v", source, reporter.Diagnostics)
+		}
+	}
+}
+
+func Test_ScanTokens_InvalidCharacters(t *testing.T) {
+	tests := map[string]struct {
+		code    string
+		message string
+		note    string
+	}{
+		"\u00b2": {errs.CodeUnexpectedCharacter, "Unexpected character '²' (U+00B2).", ""},
+		"\u0663": {errs.CodeUnexpectedCharacter, "Unexpected character '٣' (U+0663).", "numbers can only be written with the ASCII digits 0-9"},
+		"\u201c": {errs.CodeConfusableCharacter, "Unexpected character '“' (U+201C).", `this looks like '"' but is a different character`},
+		"\u2260": {errs.CodeConfusableCharacter, "Unexpected character '≠' (U+2260).", "this looks like '!=' but is a different character"},
+		"\u00a0": {errs.CodeConfusableCharacter, "Unexpected character U+00A0.", "this looks like ' ' but is a different character"},
+		"\u200b": {errs.CodeConfusableCharacter, "Unexpected character U+200B.", "this character is invisible"},
+		"\u309b": {errs.CodeUnexpectedCharacter, "Unexpected character '゛' (U+309B).", ""},
+		"@":      {errs.CodeUnexpectedCharacter, "Unexpected character '@' (U+0040).", ""},
+	}
+
+	for source, expected := range tests {
+		reporter := errs.NewCollector()
+		scanner.New(source, reporter).ScanTokens()
+		if len(reporter.Diagnostics) != 1 {
+			t.Errorf("%q: expected one diagnostic but got %v", source, reporter.Diagnostics)
+			continue
+		}
+
+		d := reporter.Diagnostics[0]
+		note := ""
+		if len(d.Notes) > 0 {
+			note = d.Notes[0]
+		}
+
+		if d.Code != expected.code || d.Message != expected.message || note != expected.note {
+			t.Errorf("%q: expected %s %q (%q) but got %s %q (%q)", source, expected.code, expected.message, expected.note, d.Code, d.Message, note)
+		}
+	}
+}
+
+func Test_ScanTokens_MixedScripts(t *testing.T) {
+	reporter := errs.NewCollector()
+	s := scanner.New("var p\u0430ssword;", reporter)
+	s.ScanTokens()
+
+	if reporter.HasErrors() {
+		t.Errorf("expected mixed scripts to only be a warning but got %v", reporter.Diagnostics)
+	}
+
+	if len(reporter.Diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic but got %v", reporter.Diagnostics)
+	}
+
+	d := reporter.Diagnostics[0]
+	if d.Severity != errs.SeverityWarning || d.Code != errs.CodeMixedScripts || d.Message != "Identifier mixes Cyrillic and Latin characters." {
+		t.Errorf("expected a mixed scripts warning but got %s %s %q", d.Severity, d.Code, d.Message)
+	}
+
+	if d.Span != span(1, 5, 4, 1, 13, 13) {
+		t.Errorf("expected the warning to cover the identifier but got %s", d.Span)
+	}
+}
+
+func Test_Lossless_RoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.lox"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("expected a corpus in testdata but found %v (%v)", files, err)
+	}
+
+	for _, file := range files {
+		bytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("%s: reading: %s", file, err)
+		}
+		source := string(bytes)
+
+		s := scanner.New(source, errs.NewCollector())
+		s.Lossless = true
+		s.ScanTokens()
+		if result := token.Source(s.Tokens()); result != source {
+			t.Errorf("%s: expected the tokens to rebuild the source but got %q", file, result)
+		}
+
+		r := scanner.NewReader(strings.NewReader(source), errs.NewCollector())
+		r.Lossless = true
+		toks := make([]*token.T, 0)
+		for tok := r.Next(); ; tok = r.Next() {
+			toks = append(toks, tok)
+			if tok.Type == token.EOF {
+				break
+			}
+		}
+		if result := token.Source(toks); result != source {
+			t.Errorf("%s: expected streamed tokens to rebuild the source but got %q", file, result)
+		}
+	}
+}
+
+func Test_Lossless_Trivia(t *testing.T) {
+	s := scanner.New("a /* b */ // c\n\n  d @ e", errs.NewCollector())
+	s.Lossless = true
+	s.ScanTokens()
+
+	expected := []struct {
+		leading, trailing string
+	}{
+		{"", "Whitespace(\" \") BlockComment(\"/* b */\") Whitespace(\" \") LineComment(\"// c\")"},
+		{"Newline(\"\\n\\n\") Whitespace(\"  \")", "Whitespace(\" \")"},
+		{"Skipped(\"@\") Whitespace(\" \")", ""},
+		{"", ""},
+	}
+
+	toks := s.Tokens()
+	if len(toks) != len(expected) {
+		t.Fatalf("expected %d tokens but got %v", len(expected), toks)
+	}
+
+	for i, tok := range toks {
+		if leading := describeTrivia(tok.Leading); leading != expected[i].leading {
+			t.Errorf("token %d (%s): expected leading trivia %s but got %s", i, tok, expected[i].leading, leading)
+		}
+
+		if trailing := describeTrivia(tok.Trailing); trailing != expected[i].trailing {
+			t.Errorf("token %d (%s): expected trailing trivia %s but got %s", i, tok, expected[i].trailing, trailing)
+		}
+	}
+}
+
+func describeTrivia(trivia []token.Trivia) string {
+	parts := make([]string, len(trivia))
+	for i, tr := range trivia {
+		parts[i] = fmt.Sprintf("%s(%q)", tr.Kind, tr.Text)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func span(sl, sc uint, so int, el, ec uint, eo int) token.Span {
+	return token.Span{
+		Start: token.Position{Line: sl, Column: sc, Offset: so},
+		End:   token.Position{Line: el, Column: ec, Offset: eo},
+	}
+}
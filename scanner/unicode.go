@@ -0,0 +1,213 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Identifiers follow the default identifier syntax of Unicode Standard
+// Annex #31 (https://unicode.org/reports/tr31/) with one extension, an
+// identifier may also begin with an underscore:
+//
+//	identifier = ( XID_Start | "_" ), { XID_Continue }
+//
+// Identifiers are normalized to NFC, so two identifiers that look the same
+// are the same even if one was typed with combining characters. Numbers are
+// only ever written with the ASCII digits 0-9.
+//
+// Go's unicode package doesn't have the XID properties but does have
+// everything needed to derive ID_Start and ID_Continue. The XID versions
+// differ from those only by the handful of characters that aren't closed
+// under NFKC normalization, listed below.
+
+var (
+	// characters in ID_Start but not XID_Start
+	notXIDStart = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 0x037a, Hi: 0x037a, Stride: 1},
+			{Lo: 0x0e33, Hi: 0x0e33, Stride: 1},
+			{Lo: 0x0eb3, Hi: 0x0eb3, Stride: 1},
+			{Lo: 0x309b, Hi: 0x309c, Stride: 1},
+			{Lo: 0xfc5e, Hi: 0xfc63, Stride: 1},
+			{Lo: 0xfdfa, Hi: 0xfdfb, Stride: 1},
+			{Lo: 0xfe70, Hi: 0xfe7e, Stride: 2},
+			{Lo: 0xff9e, Hi: 0xff9f, Stride: 1},
+		},
+	}
+
+	// characters in ID_Continue but not XID_Continue
+	notXIDContinue = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 0x037a, Hi: 0x037a, Stride: 1},
+			{Lo: 0x309b, Hi: 0x309c, Stride: 1},
+			{Lo: 0xfc5e, Hi: 0xfc63, Stride: 1},
+			{Lo: 0xfdfa, Hi: 0xfdfb, Stride: 1},
+			{Lo: 0xfe70, Hi: 0xfe7e, Stride: 2},
+		},
+	}
+
+	idStart = []*unicode.RangeTable{
+		unicode.L,
+		unicode.Nl,
+		unicode.Other_ID_Start,
+	}
+
+	idContinue = []*unicode.RangeTable{
+		unicode.L,
+		unicode.Nl,
+		unicode.Other_ID_Start,
+		unicode.Mn,
+		unicode.Mc,
+		unicode.Nd,
+		unicode.Pc,
+		unicode.Other_ID_Continue,
+	}
+)
+
+func isIdentifierStart(r rune) bool {
+	if r < utf8.RuneSelf {
+		return r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+	}
+
+	return unicode.In(r, idStart...) && !isPatternCharacter(r) && !unicode.Is(notXIDStart, r)
+}
+
+func isIdentifierContinue(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isIdentifierStart(r) || isDigit(r)
+	}
+
+	return unicode.In(r, idContinue...) && !isPatternCharacter(r) && !unicode.Is(notXIDContinue, r)
+}
+
+// isPatternCharacter returns true for the characters UAX #31 reserves for
+// the syntax of a language, which are never part of an identifier.
+func isPatternCharacter(r rune) bool {
+	return unicode.In(r, unicode.Pattern_Syntax, unicode.Pattern_White_Space)
+}
+
+// confusables maps characters that are easily mistaken for one of Lox's
+// ASCII symbols, often because a word processor or keyboard layout
+// substituted them, to the symbol they were probably meant to be.
+var confusables = map[rune]string{
+	'\u00a0': " ",  // no-break space
+	'\u00d7': "*",  // multiplication sign
+	'\u00f7': "/",  // division sign
+	'\u037e': ";",  // greek question mark
+	'\u2010': "-",  // hyphen
+	'\u2011': "-",  // non-breaking hyphen
+	'\u2012': "-",  // figure dash
+	'\u2013': "-",  // en dash
+	'\u2014': "-",  // em dash
+	'\u2018': "'",  // left single quotation mark
+	'\u2019': "'",  // right single quotation mark
+	'\u201c': "\"", // left double quotation mark
+	'\u201d': "\"", // right double quotation mark
+	'\u201e': "\"", // double low-9 quotation mark
+	'\u2024': ".",  // one dot leader
+	'\u2044': "/",  // fraction slash
+	'\u2212': "-",  // minus sign
+	'\u2215': "/",  // division slash
+	'\u2217': "*",  // asterisk operator
+	'\u2260': "!=", // not equal to
+	'\u2264': "<=", // less-than or equal to
+	'\u2265': ">=", // greater-than or equal to
+	'\u3000': " ",  // ideographic space
+	'\uff01': "!",  // fullwidth exclamation mark
+	'\uff02': "\"", // fullwidth quotation mark
+	'\uff08': "(",  // fullwidth left parenthesis
+	'\uff09': ")",  // fullwidth right parenthesis
+	'\uff0a': "*",  // fullwidth asterisk
+	'\uff0b': "+",  // fullwidth plus sign
+	'\uff0c': ",",  // fullwidth comma
+	'\uff0d': "-",  // fullwidth hyphen-minus
+	'\uff0e': ".",  // fullwidth full stop
+	'\uff0f': "/",  // fullwidth solidus
+	'\uff1a': ":",  // fullwidth colon
+	'\uff1b': ";",  // fullwidth semicolon
+	'\uff1c': "<",  // fullwidth less-than sign
+	'\uff1d': "=",  // fullwidth equals sign
+	'\uff1e': ">",  // fullwidth greater-than sign
+	'\uff1f': "?",  // fullwidth question mark
+	'\uff5b': "{",  // fullwidth left curly bracket
+	'\uff5d': "}",  // fullwidth right curly bracket
+}
+
+// describeRune names a character for a diagnostic, showing the character
+// itself only if it can be seen.
+func describeRune(r rune) string {
+	if unicode.IsGraphic(r) && !unicode.IsSpace(r) {
+		return fmt.Sprintf("'%c' (U+%04X)", r, r)
+	}
+
+	return fmt.Sprintf("U+%04X", r)
+}
+
+// scriptSets are the combinations of scripts that may be mixed in a single
+// identifier, following the Highly Restrictive level of Unicode Technical
+// Standard #39. Any identifier using a single script is also allowed.
+var scriptSets = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// mixedScripts returns the sorted names of the scripts used by the
+// identifier if they're a mix that's likely to hide a confusable
+// character, like a Cyrillic 'а' among Latin letters. It returns nil for
+// identifiers that are fine.
+func mixedScripts(ident string) []string {
+	used := make(map[string]bool)
+	for _, r := range ident {
+		if r < utf8.RuneSelf {
+			if isIdentifierStart(r) && r != '_' {
+				used["Latin"] = true
+			}
+			continue
+		}
+
+		for name, table := range unicode.Scripts {
+			if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+				used[name] = true
+				break
+			}
+		}
+	}
+
+	if len(used) < 2 {
+		return nil
+	}
+
+	for _, set := range scriptSets {
+		allowed := 0
+		for _, name := range set {
+			if used[name] {
+				allowed++
+			}
+		}
+
+		if allowed == len(used) {
+			return nil
+		}
+	}
+
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// joinNames lists names in prose, like "Cyrillic, Greek and Latin".
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
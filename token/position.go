@@ -0,0 +1,57 @@
+package token
+
+import "fmt"
+
+// Position is a location within the source. Line and Column are 1 based,
+// with Column counted in runes, and Offset is the 0 based byte offset from
+// the start of the source.
+type Position struct {
+	Line   uint
+	Column uint
+	Offset int
+}
+
+// IsValid returns true if the position has been set, the zero Position is
+// used for synthesized tokens and nodes that don't appear in the source.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns a friendly printable value representing the Position.
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Span is a range of the source from Start up to, but not including, End.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// IsValid returns true if the span has been set.
+func (s Span) IsValid() bool {
+	return s.Start.IsValid()
+}
+
+// String returns a friendly printable value representing the Span.
+func (s Span) String() string {
+	return fmt.Sprintf("%s-%s", s.Start, s.End)
+}
+
+// Cover returns the smallest span containing all of the given spans, which
+// are expected to be in source order. Spans that are not valid are ignored.
+func Cover(spans ...Span) Span {
+	var cover Span
+	for _, s := range spans {
+		if !s.IsValid() {
+			continue
+		}
+
+		if !cover.IsValid() {
+			cover.Start = s.Start
+		}
+		cover.End = s.End
+	}
+
+	return cover
+}
@@ -5,12 +5,24 @@ import "fmt"
 // T is a token value type contain data about the token such as what
 // type the token is, what string value does the token contain,
 // what converted Go value does the token represent and what line
-// was the token seen on.
+// was the token seen on. Start and End locate the token within the
+// source. The Lexeme of an identifier is normalized to NFC so it may not
+// match the source byte for byte.
+//
+// Tokens scanned in lossless mode also carry Text, exactly as it appeared
+// in the source, and the Trivia around them. Trailing trivia is everything
+// after the token on the same line, any other trivia leads the following
+// token.
 type T struct {
 	Type
-	Lexeme  string
-	Literal interface{}
-	Line    uint
+	Lexeme   string
+	Literal  interface{}
+	Line     uint
+	Start    Position
+	End      Position
+	Text     string
+	Leading  []Trivia
+	Trailing []Trivia
 }
 
 // New creates a new *T and returns it containing the information
@@ -28,3 +40,16 @@ func New(t Type, lex string, lit interface{}, line uint) *T {
 func (t *T) String() string {
 	return fmt.Sprintf("%s %s %v", t.Type, t.Lexeme, t.Literal)
 }
+
+// Span returns the range of the source the token was scanned from. A nil
+// token, one that's missing from a syntax tree, has an invalid span.
+func (t *T) Span() Span {
+	if t == nil {
+		return Span{}
+	}
+
+	return Span{
+		Start: t.Start,
+		End:   t.End,
+	}
+}
@@ -0,0 +1,60 @@
+package token
+
+import "strings"
+
+// TriviaKind says what sort of source text a piece of Trivia holds.
+type TriviaKind uint8
+
+// The kinds of Trivia that can surround a token. SkippedTrivia is source
+// the scanner couldn't make a token from, such as an unexpected character,
+// kept so that no part of the source is lost.
+const (
+	WhitespaceTrivia TriviaKind = iota
+	NewlineTrivia
+	LineCommentTrivia
+	BlockCommentTrivia
+	SkippedTrivia
+)
+
+// String returns a friendly printable name for the TriviaKind value.
+func (k TriviaKind) String() string {
+	switch k {
+	case WhitespaceTrivia:
+		return "Whitespace"
+	case NewlineTrivia:
+		return "Newline"
+	case LineCommentTrivia:
+		return "LineComment"
+	case BlockCommentTrivia:
+		return "BlockComment"
+	case SkippedTrivia:
+		return "Skipped"
+	}
+
+	return "UNKNOWN"
+}
+
+// Trivia is source text between tokens that has no meaning to the parser,
+// but that tools like formatters need to preserve.
+type Trivia struct {
+	Kind TriviaKind
+	Text string
+}
+
+// Source rebuilds the source text the tokens were scanned from by joining
+// the text and trivia of each token. The tokens must have been scanned in
+// lossless mode.
+func Source(toks []*T) string {
+	var sb strings.Builder
+	for _, tok := range toks {
+		for _, tr := range tok.Leading {
+			sb.WriteString(tr.Text)
+		}
+		sb.WriteString(tok.Text)
+		for _, tr := range tok.Trailing {
+			sb.WriteString(tr.Text)
+		}
+	}
+
+	return sb.String()
+}
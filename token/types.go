@@ -18,6 +18,7 @@ const (
 	Semicolon
 	Slash
 	Star
+	Percent
 	QuestionMark
 	Colon
 
@@ -35,6 +36,7 @@ const (
 	Identifier
 	String
 	Number
+	Interpolation
 
 	// Kenywords
 	And
@@ -83,6 +85,8 @@ func (t Type) String() string {
 		return "Slash"
 	case Star:
 		return "Star"
+	case Percent:
+		return "Percent"
 	case QuestionMark:
 		return "QuestionMark"
 	case Colon:
@@ -109,6 +113,8 @@ func (t Type) String() string {
 		return "String"
 	case Number:
 		return "Number"
+	case Interpolation:
+		return "Interpolation"
 	case And:
 		return "And"
 	case Class:
@@ -0,0 +1,28 @@
+package expr
+
+import "github.com/bbuck/glox/token"
+
+// Assign represents storing the result of an expression into an
+// existing named variable.
+type Assign struct {
+	Name  *token.T
+	Value Expr
+}
+
+// NewAssign constructs and returns a new Assign expression.
+func NewAssign(name *token.T, value Expr) *Assign {
+	return &Assign{
+		Name:  name,
+		Value: value,
+	}
+}
+
+// Accept for Assign calls the VisitAssign method on the visitor.
+func (a *Assign) Accept(v Visitor) {
+	v.VisitAssign(a)
+}
+
+// Span for Assign covers the name through the assigned value.
+func (a *Assign) Span() token.Span {
+	return token.Cover(a.Name.Span(), a.Value.Span())
+}
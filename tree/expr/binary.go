@@ -23,3 +23,8 @@ func NewBinary(left Expr, op *token.T, right Expr) *Binary {
 func (b *Binary) Accept(v Visitor) {
 	v.VisitBinary(b)
 }
+
+// Span for Binary covers both operands.
+func (b *Binary) Span() token.Span {
+	return token.Cover(b.Left.Span(), b.Operator.Span(), b.Right.Span())
+}
@@ -0,0 +1,31 @@
+package expr
+
+import "github.com/bbuck/glox/token"
+
+// Call represents calling the result of the Callee expression with the
+// given list of arguments. Paren is the closing parenthesis of the argument
+// list and is used to report errors that occur when making the call.
+type Call struct {
+	Callee    Expr
+	Paren     *token.T
+	Arguments []Expr
+}
+
+// NewCall constructs and returns a new Call expression.
+func NewCall(callee Expr, paren *token.T, args []Expr) *Call {
+	return &Call{
+		Callee:    callee,
+		Paren:     paren,
+		Arguments: args,
+	}
+}
+
+// Accept for Call calls the VisitCall method on the visitor.
+func (c *Call) Accept(v Visitor) {
+	v.VisitCall(c)
+}
+
+// Span for Call covers the callee through the closing parenthesis.
+func (c *Call) Span() token.Span {
+	return token.Cover(c.Callee.Span(), c.Paren.Span())
+}
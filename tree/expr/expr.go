@@ -1,6 +1,11 @@
 package expr
 
-// Expr is an expression interface that defines an Accept method
+import "github.com/bbuck/glox/token"
+
+// Expr is an expression interface that defines an Accept method and
+// a Span method returning the range of source the expression was parsed
+// from.
 type Expr interface {
 	Accept(Visitor)
+	Span() token.Span
 }
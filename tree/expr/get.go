@@ -0,0 +1,28 @@
+package expr
+
+import "github.com/bbuck/glox/token"
+
+// Get represents accessing a named property on the result of the Object
+// expression.
+type Get struct {
+	Object Expr
+	Name   *token.T
+}
+
+// NewGet constructs and returns a new Get expression.
+func NewGet(object Expr, name *token.T) *Get {
+	return &Get{
+		Object: object,
+		Name:   name,
+	}
+}
+
+// Accept for Get calls the VisitGet method on the visitor.
+func (g *Get) Accept(v Visitor) {
+	v.VisitGet(g)
+}
+
+// Span for Get covers the object through the property name.
+func (g *Get) Span() token.Span {
+	return token.Cover(g.Object.Span(), g.Name.Span())
+}
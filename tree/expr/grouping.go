@@ -1,16 +1,22 @@
 package expr
 
+import "github.com/bbuck/glox/token"
+
 // Grouping is an expression wrapped in parenthesis, so it represents
 // a grouped single or set of expressions.
 type Grouping struct {
+	LeftParen  *token.T
 	Expression Expr
+	RightParen *token.T
 }
 
 // NewGrouping returns a new Grouping expression containing the wrapped
-// expression.
-func NewGrouping(expr Expr) *Grouping {
+// expression and the parenthesis surrounding it.
+func NewGrouping(left *token.T, expr Expr, right *token.T) *Grouping {
 	return &Grouping{
+		LeftParen:  left,
 		Expression: expr,
+		RightParen: right,
 	}
 }
 
@@ -18,3 +24,8 @@ func NewGrouping(expr Expr) *Grouping {
 func (g *Grouping) Accept(v Visitor) {
 	v.VisitGrouping(g)
 }
+
+// Span for Grouping covers the opening through the closing parenthesis.
+func (g *Grouping) Span() token.Span {
+	return token.Cover(g.LeftParen.Span(), g.Expression.Span(), g.RightParen.Span())
+}
@@ -0,0 +1,31 @@
+package expr
+
+import "github.com/bbuck/glox/token"
+
+// Interpolation represents a string with expressions embedded in it, like
+// `"Hello ${name}!"`. Parts alternates between string Literals and the
+// embedded expressions, beginning and ending with a (possibly empty) string
+// Literal, so the parts above would be "Hello ", name and "!".
+type Interpolation struct {
+	Parts []Expr
+}
+
+// NewInterpolation constructs a new Interpolation expression from its
+// parts.
+func NewInterpolation(parts []Expr) *Interpolation {
+	return &Interpolation{
+		Parts: parts,
+	}
+}
+
+// Accept for Interpolation calls the VisitInterpolation method on the
+// visitor.
+func (i *Interpolation) Accept(v Visitor) {
+	v.VisitInterpolation(i)
+}
+
+// Span for Interpolation covers the string from its opening quote to its
+// closing quote.
+func (i *Interpolation) Span() token.Span {
+	return token.Cover(i.Parts[0].Span(), i.Parts[len(i.Parts)-1].Span())
+}
@@ -0,0 +1,35 @@
+package expr
+
+import "github.com/bbuck/glox/token"
+
+// Lambda represents an anonymous function expression such as
+// `fun (a, b) { return a + b; }`. The Body contains the stmt.Stmt values
+// that make up the function, they're stored as interface{} values since
+// the stmt package already depends on this one.
+type Lambda struct {
+	Keyword    *token.T
+	Params     []*token.T
+	Body       []interface{}
+	RightBrace *token.T
+}
+
+// NewLambda constructs and returns a new Lambda expression, rbrace is the
+// brace closing the function body.
+func NewLambda(keyword *token.T, params []*token.T, body []interface{}, rbrace *token.T) *Lambda {
+	return &Lambda{
+		Keyword:    keyword,
+		Params:     params,
+		Body:       body,
+		RightBrace: rbrace,
+	}
+}
+
+// Accept for Lambda calls the VisitLambda method on the visitor.
+func (l *Lambda) Accept(v Visitor) {
+	v.VisitLambda(l)
+}
+
+// Span for Lambda covers the fun keyword through the closing brace.
+func (l *Lambda) Span() token.Span {
+	return token.Cover(l.Keyword.Span(), l.RightBrace.Span())
+}
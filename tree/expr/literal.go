@@ -1,7 +1,10 @@
 package expr
 
+import "github.com/bbuck/glox/token"
+
 // LiteralType represents why type of data the literal contains
-// letting us know what to expect in the Value field.
+// letting us know what to expect in the Value field. The Value of a
+// NumberLiteral is a float64 and of an IntegerLiteral an int64.
 type LiteralType uint8
 
 // The various kinds of values that a Literal expression will contain
@@ -11,12 +14,36 @@ const (
 	KeywordLiteral
 	BooleanLiteral
 	NilLiteral
+	IntegerLiteral
 )
 
-// Literal represents a value found literally in the code.
+// String returns the name of the literal type.
+func (t LiteralType) String() string {
+	switch t {
+	case StringLiteral:
+		return "String"
+	case NumberLiteral:
+		return "Number"
+	case KeywordLiteral:
+		return "Keyword"
+	case BooleanLiteral:
+		return "Boolean"
+	case NilLiteral:
+		return "Nil"
+	case IntegerLiteral:
+		return "Integer"
+	}
+
+	return "UNKNOWN"
+}
+
+// Literal represents a value found literally in the code. Token is the
+// token the value was parsed from and will be nil for literals that were
+// synthesized rather than parsed.
 type Literal struct {
 	Type  LiteralType
 	Value interface{}
+	Token *token.T
 }
 
 // NewLiteral constructs a new Literal expression with the given type
@@ -32,3 +59,12 @@ func NewLiteral(typ LiteralType, value interface{}) *Literal {
 func (l *Literal) Accept(v Visitor) {
 	v.VisitLiteral(l)
 }
+
+// Span for Literal is the span of its token, if it has one.
+func (l *Literal) Span() token.Span {
+	if l.Token == nil {
+		return token.Span{}
+	}
+
+	return l.Token.Span()
+}
@@ -0,0 +1,31 @@
+package expr
+
+import "github.com/bbuck/glox/token"
+
+// Logical represents an `and` or `or` expression. These are kept separate
+// from Binary because they short-circuit, the right operand is only
+// evaluated when the left operand does not already decide the result.
+type Logical struct {
+	Left     Expr
+	Operator *token.T
+	Right    Expr
+}
+
+// NewLogical constructs and returns a new Logical expression.
+func NewLogical(left Expr, op *token.T, right Expr) *Logical {
+	return &Logical{
+		Left:     left,
+		Operator: op,
+		Right:    right,
+	}
+}
+
+// Accept for Logical calls the VisitLogical method on the visitor.
+func (l *Logical) Accept(v Visitor) {
+	v.VisitLogical(l)
+}
+
+// Span for Logical covers both operands.
+func (l *Logical) Span() token.Span {
+	return token.Cover(l.Left.Span(), l.Operator.Span(), l.Right.Span())
+}
@@ -1,5 +1,7 @@
 package expr
 
+import "github.com/bbuck/glox/token"
+
 // Sequenced represents an expression that should be executed first,
 // the result discarded, and then a following expression should be
 // executed. Like `do_the_first_thing(), do_the_second(), clean_up()`
@@ -21,3 +23,8 @@ func NewSequenced(left, right Expr) *Sequenced {
 func (s *Sequenced) Accept(v Visitor) {
 	v.VisitSequenced(s)
 }
+
+// Span for Sequenced covers both expressions.
+func (s *Sequenced) Span() token.Span {
+	return token.Cover(s.Left.Span(), s.Right.Span())
+}
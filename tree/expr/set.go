@@ -0,0 +1,30 @@
+package expr
+
+import "github.com/bbuck/glox/token"
+
+// Set represents storing the result of the Value expression into a named
+// field on the result of the Object expression.
+type Set struct {
+	Object Expr
+	Name   *token.T
+	Value  Expr
+}
+
+// NewSet constructs and returns a new Set expression.
+func NewSet(object Expr, name *token.T, value Expr) *Set {
+	return &Set{
+		Object: object,
+		Name:   name,
+		Value:  value,
+	}
+}
+
+// Accept for Set calls the VisitSet method on the visitor.
+func (s *Set) Accept(v Visitor) {
+	v.VisitSet(s)
+}
+
+// Span for Set covers the object through the assigned value.
+func (s *Set) Span() token.Span {
+	return token.Cover(s.Object.Span(), s.Name.Span(), s.Value.Span())
+}
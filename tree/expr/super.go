@@ -0,0 +1,28 @@
+package expr
+
+import "github.com/bbuck/glox/token"
+
+// Super represents accessing a method from the superclass of the class
+// the current method was defined in, like `super.init()`.
+type Super struct {
+	Keyword *token.T
+	Method  *token.T
+}
+
+// NewSuper constructs and returns a new Super expression.
+func NewSuper(keyword, method *token.T) *Super {
+	return &Super{
+		Keyword: keyword,
+		Method:  method,
+	}
+}
+
+// Accept for Super calls the VisitSuper method on the visitor.
+func (s *Super) Accept(v Visitor) {
+	v.VisitSuper(s)
+}
+
+// Span for Super covers the keyword through the method name.
+func (s *Super) Span() token.Span {
+	return token.Cover(s.Keyword.Span(), s.Method.Span())
+}
@@ -1,5 +1,7 @@
 package expr
 
+import "github.com/bbuck/glox/token"
+
 // Ternary represents an inline 3-expression operation with a
 // condition and two other expressions. If the result of the
 // condition is true-thy then the result of the Positive
@@ -25,3 +27,8 @@ func NewTernary(cond, pos, neg Expr) *Ternary {
 func (t *Ternary) Accept(v Visitor) {
 	v.VisitTernary(t)
 }
+
+// Span for Ternary covers the condition through the negative branch.
+func (t *Ternary) Span() token.Span {
+	return token.Cover(t.Condition.Span(), t.Positive.Span(), t.Negative.Span())
+}
@@ -0,0 +1,25 @@
+package expr
+
+import "github.com/bbuck/glox/token"
+
+// This represents a reference to the instance a method was called on.
+type This struct {
+	Keyword *token.T
+}
+
+// NewThis constructs and returns a new This expression.
+func NewThis(keyword *token.T) *This {
+	return &This{
+		Keyword: keyword,
+	}
+}
+
+// Accept for This calls the VisitThis method on the visitor.
+func (t *This) Accept(v Visitor) {
+	v.VisitThis(t)
+}
+
+// Span for This is the span of the keyword.
+func (t *This) Span() token.Span {
+	return t.Keyword.Span()
+}
@@ -21,3 +21,8 @@ func NewUnary(op *token.T, right Expr) *Unary {
 func (u *Unary) Accept(v Visitor) {
 	v.VisitUnary(u)
 }
+
+// Span for Unary covers the operator and the operand.
+func (u *Unary) Span() token.Span {
+	return token.Cover(u.Operator.Span(), u.Right.Span())
+}
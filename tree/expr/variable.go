@@ -0,0 +1,26 @@
+package expr
+
+import "github.com/bbuck/glox/token"
+
+// Variable represents a reference to a named variable whose value should
+// be looked up when the expression is evaluated.
+type Variable struct {
+	Name *token.T
+}
+
+// NewVariable constructs and returns a new Variable expression.
+func NewVariable(name *token.T) *Variable {
+	return &Variable{
+		Name: name,
+	}
+}
+
+// Accept for Variable calls the VisitVariable method on the visitor.
+func (vr *Variable) Accept(v Visitor) {
+	v.VisitVariable(vr)
+}
+
+// Span for Variable is the span of the name.
+func (vr *Variable) Span() token.Span {
+	return vr.Name.Span()
+}
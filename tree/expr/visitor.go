@@ -9,4 +9,14 @@ type Visitor interface {
 	VisitUnary(*Unary)
 	VisitSequenced(*Sequenced)
 	VisitTernary(*Ternary)
+	VisitVariable(*Variable)
+	VisitAssign(*Assign)
+	VisitLogical(*Logical)
+	VisitCall(*Call)
+	VisitLambda(*Lambda)
+	VisitGet(*Get)
+	VisitSet(*Set)
+	VisitThis(*This)
+	VisitSuper(*Super)
+	VisitInterpolation(*Interpolation)
 }
@@ -10,7 +10,24 @@ import (
 // ParseError represents a failure parsing the program.
 var ParseError = errors.New("Parse Error")
 
-func parseError(tok *token.T, msg string) error {
-	errs.TokenError(tok, msg)
-	return ParseError
+// Error describes a single syntax error, the token the parser was looking
+// at when the error occurred and a message explaining what was expected.
+type Error struct {
+	Token   *token.T
+	Code    string
+	Message string
+}
+
+// Error returns the message describing the failure.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (p *P) error(tok *token.T, code, msg string) error {
+	p.reporter.Report(errs.NewError(code, tok.Span(), msg))
+	return &Error{
+		Token:   tok,
+		Code:    code,
+		Message: msg,
+	}
 }
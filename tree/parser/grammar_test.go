@@ -0,0 +1,545 @@
+package parser_test
+
+// These tests keep grammar.ebnf and the recursive descent parser in sync.
+// The grammar file is parsed and, for every production, the parser method
+// implementing it is checked to reference the tokens for each terminal and
+// to reach the methods of each production it refers to.
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+const grammarFile = "../../grammar.ebnf"
+
+// productionMethods maps each grammar production to the parser methods that
+// implement it, the first being the method called to parse the production.
+// Productions that are inlined into another rule's method map to that
+// method.
+var productionMethods = map[string][]string{
+	"program":        {"ParseProgram"},
+	"declaration":    {"declarationOrStatement"},
+	"classDecl":      {"classDeclaration"},
+	"funDecl":        {"declarationOrStatement"},
+	"function":       {"function"},
+	"functionBody":   {"functionBody"},
+	"parameters":     {"functionBody"},
+	"varDecl":        {"varDeclaration"},
+	"statement":      {"statement"},
+	"exprStmt":       {"expressionStatement"},
+	"forStmt":        {"forStatement"},
+	"ifStmt":         {"ifStatement"},
+	"printStmt":      {"printStatement"},
+	"returnStmt":     {"returnStatement"},
+	"whileStmt":      {"whileStatement"},
+	"block":          {"block"},
+	"expression":     {"expression"},
+	"sequenced":      {"sequenced"},
+	"assignment":     {"assignment"},
+	"ternary":        {"ternary"},
+	"logic_or":       {"or"},
+	"logic_and":      {"and"},
+	"equality":       {"equality"},
+	"comparison":     {"comparison"},
+	"addition":       {"addition"},
+	"multiplication": {"multiplication"},
+	"unary":          {"unary"},
+	"call":           {"call", "finishCall"},
+	"arguments":      {"finishCall"},
+	"primary":        {"primary"},
+	"interpolation":  {"interpolation"},
+}
+
+// coverProductions are parsed as a more general expression which is then
+// reinterpreted, so their terminals are never matched directly and the
+// productions they refer to are only reached through that expression.
+var coverProductions = map[string]bool{
+	"assignment": true,
+}
+
+// tokenClasses are the grammar's names for tokens that carry a value.
+var tokenClasses = map[string]string{
+	"IDENTIFIER":    "Identifier",
+	"NUMBER":        "Number",
+	"STRING":        "String",
+	"INTERPOLATION": "Interpolation",
+	"EOF":           "",
+}
+
+var symbolTokens = map[string]string{
+	"(":  "LeftParen",
+	")":  "RightParen",
+	"{":  "LeftBrace",
+	"}":  "RightBrace",
+	",":  "Comma",
+	".":  "Dot",
+	"-":  "Minus",
+	"+":  "Plus",
+	";":  "Semicolon",
+	"/":  "Slash",
+	"*":  "Star",
+	"%":  "Percent",
+	"?":  "QuestionMark",
+	":":  "Colon",
+	"!":  "Bang",
+	"!=": "BangEqual",
+	"=":  "Equal",
+	"==": "EqualEqual",
+	">":  "Greater",
+	">=": "GreaterEqual",
+	"<":  "Less",
+	"<=": "LessEqual",
+}
+
+func Test_Grammar_ProductionsAreDefined(t *testing.T) {
+	g := loadGrammar(t)
+
+	for _, name := range g.order {
+		for ref := range g.productions[name].nonterminals {
+			if _, ok := g.productions[ref]; !ok {
+				t.Errorf("%s: refers to undefined production %s", name, ref)
+			}
+		}
+	}
+
+	reached := map[string]bool{g.order[0]: true}
+	queue := []string{g.order[0]}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for ref := range g.productions[name].nonterminals {
+			if _, ok := g.productions[ref]; ok && !reached[ref] {
+				reached[ref] = true
+				queue = append(queue, ref)
+			}
+		}
+	}
+
+	for _, name := range g.order {
+		if !reached[name] {
+			t.Errorf("%s: production is unreachable from %s", name, g.order[0])
+		}
+	}
+}
+
+func Test_Grammar_ProductionsAreImplemented(t *testing.T) {
+	g := loadGrammar(t)
+	methods := loadMethods(t)
+
+	callers := make(map[string][]string)
+	for _, name := range g.order {
+		for ref := range g.productions[name].nonterminals {
+			callers[ref] = append(callers[ref], name)
+		}
+	}
+
+	for _, name := range g.order {
+		prod := g.productions[name]
+
+		names, ok := productionMethods[name]
+		if !ok {
+			t.Errorf("%s: no parser method is mapped to the production", name)
+			continue
+		}
+
+		missing := false
+		for _, methodName := range names {
+			if _, ok := methods[methodName]; !ok {
+				t.Errorf("%s: parser method %s does not exist", name, methodName)
+				missing = true
+			}
+		}
+
+		if missing {
+			continue
+		}
+
+		methodName := names[0]
+		for ref := range prod.nonterminals {
+			refMethod := productionMethods[ref][0]
+			if refMethod != methodName && !methods.reaches(methodName, refMethod, coverProductions[name]) {
+				t.Errorf("%s: %s never calls %s to parse %s", name, methodName, refMethod, ref)
+			}
+		}
+
+		if coverProductions[name] {
+			continue
+		}
+
+		// a terminal is usually matched by the production's own methods but
+		// may instead be matched by a caller choosing between alternatives
+		candidates := append([]string{}, names...)
+		for _, caller := range callers[name] {
+			candidates = append(candidates, productionMethods[caller]...)
+		}
+
+		for term := range prod.terminals {
+			typ, err := tokenType(term)
+			if err != nil {
+				t.Errorf("%s: %s", name, err)
+				continue
+			}
+
+			if typ == "" {
+				continue
+			}
+
+			found := false
+			for _, candidate := range candidates {
+				if methods[candidate] != nil && methods[candidate].tokens[typ] {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				t.Errorf("%s: terminal %q (token.%s) is never matched by %s", name, term, typ, strings.Join(candidates, ", "))
+			}
+		}
+	}
+}
+
+// grammar loading
+
+type production struct {
+	terminals    map[string]bool
+	nonterminals map[string]bool
+}
+
+type grammar struct {
+	order       []string
+	productions map[string]*production
+}
+
+func loadGrammar(t *testing.T) *grammar {
+	src, err := ioutil.ReadFile(grammarFile)
+	if err != nil {
+		t.Fatalf("reading grammar: %s", err)
+	}
+
+	g, err := parseGrammar(string(src))
+	if err != nil {
+		t.Fatalf("parsing grammar: %s", err)
+	}
+
+	return g
+}
+
+// parseGrammar reads the subset of ISO EBNF used by grammar.ebnf:
+//
+//	production    = NAME, "=", alternation, ";" ;
+//	alternation   = concatenation, { "|", concatenation } ;
+//	concatenation = term, { ",", term } ;
+//	term          = NAME | TERMINAL
+//	              | "[", alternation, "]"
+//	              | "{", alternation, "}"
+//	              | "(", alternation, ")" ;
+//
+// Comments, (* like this *), are skipped.
+func parseGrammar(src string) (*grammar, error) {
+	ep := &ebnfParser{
+		toks: lexGrammar(src),
+	}
+	g := &grammar{
+		productions: make(map[string]*production),
+	}
+
+	for !ep.done() {
+		name := ep.next()
+		if !isName(name) {
+			return nil, fmt.Errorf("expected production name but got %q", name)
+		}
+
+		if _, ok := g.productions[name]; ok {
+			return nil, fmt.Errorf("%s: production defined more than once", name)
+		}
+
+		if err := ep.expect("="); err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		ep.prod = &production{
+			terminals:    make(map[string]bool),
+			nonterminals: make(map[string]bool),
+		}
+		if err := ep.alternation(); err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		if err := ep.expect(";"); err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		g.order = append(g.order, name)
+		g.productions[name] = ep.prod
+	}
+
+	if len(g.order) == 0 {
+		return nil, fmt.Errorf("grammar contains no productions")
+	}
+
+	return g, nil
+}
+
+type ebnfParser struct {
+	toks []string
+	pos  int
+	prod *production
+}
+
+func (ep *ebnfParser) done() bool {
+	return ep.pos >= len(ep.toks)
+}
+
+func (ep *ebnfParser) peek() string {
+	if ep.done() {
+		return ""
+	}
+
+	return ep.toks[ep.pos]
+}
+
+func (ep *ebnfParser) next() string {
+	tok := ep.peek()
+	ep.pos++
+
+	return tok
+}
+
+func (ep *ebnfParser) expect(tok string) error {
+	if got := ep.next(); got != tok {
+		return fmt.Errorf("expected %q but got %q", tok, got)
+	}
+
+	return nil
+}
+
+func (ep *ebnfParser) alternation() error {
+	for {
+		if err := ep.concatenation(); err != nil {
+			return err
+		}
+
+		if ep.peek() != "|" {
+			return nil
+		}
+		ep.next()
+	}
+}
+
+func (ep *ebnfParser) concatenation() error {
+	for {
+		if err := ep.term(); err != nil {
+			return err
+		}
+
+		if ep.peek() != "," {
+			return nil
+		}
+		ep.next()
+	}
+}
+
+func (ep *ebnfParser) term() error {
+	tok := ep.next()
+	switch {
+	case tok == "[":
+		return ep.group("]")
+	case tok == "{":
+		return ep.group("}")
+	case tok == "(":
+		return ep.group(")")
+	case isTerminal(tok):
+		ep.prod.terminals[tok[1:len(tok)-1]] = true
+	case isName(tok) && tokenClasses[tok] != "":
+		ep.prod.terminals[tok] = true
+	case isName(tok):
+		if _, ok := tokenClasses[tok]; !ok {
+			ep.prod.nonterminals[tok] = true
+		}
+	default:
+		return fmt.Errorf("unexpected %q", tok)
+	}
+
+	return nil
+}
+
+func (ep *ebnfParser) group(closing string) error {
+	if err := ep.alternation(); err != nil {
+		return err
+	}
+
+	return ep.expect(closing)
+}
+
+func lexGrammar(src string) []string {
+	toks := make([]string, 0)
+	runes := []rune(src)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			// skip
+		case r == '(' && i+1 < len(runes) && runes[i+1] == '*':
+			// skip comments
+			for i += 2; i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == ')'); i++ {
+			}
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			for i++; i < len(runes) && runes[i] != r; i++ {
+			}
+			toks = append(toks, string(runes[start:i+1]))
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i+1 < len(runes) && (runes[i+1] == '_' || unicode.IsLetter(runes[i+1])) {
+				i++
+			}
+			toks = append(toks, string(runes[start:i+1]))
+		default:
+			toks = append(toks, string(r))
+		}
+	}
+
+	return toks
+}
+
+func isName(tok string) bool {
+	return len(tok) > 0 && (tok[0] == '_' || unicode.IsLetter(rune(tok[0])))
+}
+
+func isTerminal(tok string) bool {
+	return len(tok) >= 3 && (tok[0] == '"' || tok[0] == '\'') && tok[len(tok)-1] == tok[0]
+}
+
+// tokenType returns the name of the token.Type constant that the scanner
+// produces for the grammar terminal.
+func tokenType(term string) (string, error) {
+	if typ, ok := tokenClasses[term]; ok {
+		return typ, nil
+	}
+
+	if typ, ok := symbolTokens[term]; ok {
+		return typ, nil
+	}
+
+	if isName(term) {
+		return strings.ToUpper(term[:1]) + term[1:], nil
+	}
+
+	return "", fmt.Errorf("no token type known for terminal %q", term)
+}
+
+// parser method loading
+
+type method struct {
+	tokens map[string]bool
+	calls  map[string]bool
+}
+
+type methodSet map[string]*method
+
+// reaches reports whether from calls to, either directly or through helper
+// methods that do not implement a production of their own. When transitive
+// is true any method may be passed through.
+func (ms methodSet) reaches(from, to string, transitive bool) bool {
+	implementsProduction := make(map[string]bool)
+	for _, names := range productionMethods {
+		for _, name := range names {
+			implementsProduction[name] = !transitive
+		}
+	}
+
+	seen := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		m := ms[queue[0]]
+		queue = queue[1:]
+		if m == nil {
+			continue
+		}
+
+		for call := range m.calls {
+			if call == to {
+				return true
+			}
+
+			if !seen[call] && !implementsProduction[call] {
+				seen[call] = true
+				queue = append(queue, call)
+			}
+		}
+	}
+
+	return false
+}
+
+func loadMethods(t *testing.T) methodSet {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatalf("listing parser sources: %s", err)
+	}
+
+	methods := make(methodSet)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		f, err := goparser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("parsing %s: %s", file, err)
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Body == nil {
+				continue
+			}
+
+			methods[fn.Name.Name] = inspectMethod(fn)
+		}
+	}
+
+	return methods
+}
+
+func inspectMethod(fn *ast.FuncDecl) *method {
+	recv := ""
+	if names := fn.Recv.List[0].Names; len(names) > 0 {
+		recv = names[0].Name
+	}
+
+	m := &method{
+		tokens: make(map[string]bool),
+		calls:  make(map[string]bool),
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		if id, ok := sel.X.(*ast.Ident); ok {
+			switch id.Name {
+			case "token":
+				m.tokens[sel.Sel.Name] = true
+			case recv:
+				m.calls[sel.Sel.Name] = true
+			}
+		}
+
+		return true
+	})
+
+	return m
+}
@@ -1,38 +1,348 @@
 package parser
 
 import (
+	"github.com/bbuck/glox/errs"
 	"github.com/bbuck/glox/token"
 	"github.com/bbuck/glox/tree/expr"
+	"github.com/bbuck/glox/tree/stmt"
 )
 
+// maxArguments is the largest number of arguments a call can pass, or
+// parameters a function can declare.
+const maxArguments = 255
+
+// streamWindow is how many consumed tokens a streaming parser will hold on
+// to before discarding them.
+const streamWindow = 64
+
+// TokenSource hands out tokens one at a time, returning an EOF token once
+// there are no more. *scanner.S is a TokenSource.
+type TokenSource interface {
+	Next() *token.T
+}
+
 // P encapsulates the parsers current state allowing further calls to parse
 // to maintain positonal information within the token list.
 type P struct {
-	tokens  []*token.T
-	current int
-	Err     error
+	tokens   []*token.T
+	source   TokenSource
+	reporter errs.Reporter
+	current  int
+	errors   []*Error
+	Err      error
 }
 
 // New constructs a new parser with the token list and returns it ready for
-// use.
-func New(toks []*token.T) *P {
+// use. Any syntax errors are reported to the reporter.
+func New(toks []*token.T, reporter errs.Reporter) *P {
+	return &P{
+		tokens:   toks,
+		reporter: reporter,
+	}
+}
+
+// NewStream constructs a new parser that pulls tokens from the source only
+// as it needs them, so the full list of tokens is never built up.
+func NewStream(source TokenSource, reporter errs.Reporter) *P {
 	return &P{
-		tokens: toks,
+		tokens:   make([]*token.T, 0, streamWindow),
+		source:   source,
+		reporter: reporter,
 	}
 }
 
 // Parse returns the top-most expression in the syntax tree parsed from the
-// token list. If a parse error occurred this will return nil instead.
+// token list, which must contain nothing but the expression. If a parse
+// error occurred this will return nil instead.
 func (p *P) Parse() expr.Expr {
 	ex := p.expression()
+	if p.Err == nil && !p.isAtEnd() {
+		p.Err = p.error(p.peek(), errs.CodeSyntax, "Expect end of expression")
+	}
+
 	if p.Err == nil {
 		return ex
 	}
 
-	// TODO: Do something because of error
 	return nil
 }
 
+// ParseProgram parses the token list as a series of statements and returns
+// them in the order they appear in the source. When a statement fails to
+// parse the error is recorded and parsing resumes at the next statement so
+// that every error in the program is reported. If any parse error occurred
+// this will return nil instead and Err will be set to ParseError.
+func (p *P) ParseProgram() []stmt.Stmt {
+	stmts := make([]stmt.Stmt, 0)
+	for !p.isAtEnd() {
+		if st := p.declaration(); st != nil {
+			stmts = append(stmts, st)
+		}
+	}
+
+	if len(p.errors) == 0 {
+		return stmts
+	}
+
+	p.Err = ParseError
+
+	return nil
+}
+
+// Errors returns every parse error encountered by ParseProgram in the order
+// they were found.
+func (p *P) Errors() []*Error {
+	return p.errors
+}
+
+// declaration parses a single declaration and is the point where the
+// parser recovers from errors, discarding tokens until the start of the next
+// statement.
+func (p *P) declaration() stmt.Stmt {
+	st := p.declarationOrStatement()
+	if p.Err != nil {
+		p.errors = append(p.errors, p.Err.(*Error))
+		p.synchronize()
+
+		return nil
+	}
+
+	return st
+}
+
+func (p *P) declarationOrStatement() stmt.Stmt {
+	if p.Err != nil {
+		return nil
+	}
+
+	switch {
+	case p.match(token.Class):
+		return p.classDeclaration()
+	case p.check(token.Fun) && p.checkNext(token.Identifier):
+		p.advance()
+		fn := p.function("function")
+		if fn == nil {
+			return nil
+		}
+
+		return fn
+	case p.match(token.Var):
+		return p.varDeclaration()
+	}
+
+	return p.statement()
+}
+
+func (p *P) classDeclaration() stmt.Stmt {
+	name := p.consume(token.Identifier, "Expect class name")
+
+	var superclass *expr.Variable
+	if p.match(token.Less) {
+		p.consume(token.Identifier, "Expect superclass name")
+		superclass = expr.NewVariable(p.previous())
+	}
+
+	p.consume(token.LeftBrace, "Expect '{' before class body")
+
+	methods := make([]*stmt.Function, 0)
+	for p.Err == nil && !p.check(token.RightBrace) && !p.isAtEnd() {
+		methods = append(methods, p.function("method"))
+	}
+
+	p.consume(token.RightBrace, "Expect '}' after class body")
+
+	if p.Err != nil {
+		return nil
+	}
+
+	return stmt.NewClass(name, superclass, methods)
+}
+
+// function parses the name, parameters and body of a function declaration,
+// kind is used to describe what is being parsed in error messages.
+func (p *P) function(kind string) *stmt.Function {
+	name := p.consume(token.Identifier, "Expect "+kind+" name")
+	p.consume(token.LeftParen, "Expect '(' after "+kind+" name")
+	params, body := p.functionBody(kind)
+	if p.Err != nil {
+		return nil
+	}
+
+	return stmt.NewFunction(name, params, body)
+}
+
+// functionBody parses the parameter list, following the opening '(', and the
+// block body of a function.
+func (p *P) functionBody(kind string) ([]*token.T, []stmt.Stmt) {
+	params := make([]*token.T, 0)
+	if !p.check(token.RightParen) {
+		for {
+			if len(params) >= maxArguments {
+				p.Err = p.error(p.peek(), errs.CodeTooManyArguments, "Cannot have more than 255 parameters")
+				return nil, nil
+			}
+
+			params = append(params, p.consume(token.Identifier, "Expect parameter name"))
+
+			if !p.match(token.Comma) {
+				break
+			}
+		}
+	}
+	p.consume(token.RightParen, "Expect ')' after parameters")
+
+	p.consume(token.LeftBrace, "Expect '{' before "+kind+" body")
+	body := p.block()
+
+	return params, body
+}
+
+func (p *P) varDeclaration() stmt.Stmt {
+	name := p.consume(token.Identifier, "Expect variable name")
+
+	var init expr.Expr
+	if p.match(token.Equal) {
+		init = p.expression()
+	}
+
+	p.consume(token.Semicolon, "Expect ';' after variable declaration")
+
+	return stmt.NewVar(name, init)
+}
+
+func (p *P) statement() stmt.Stmt {
+	if p.Err != nil {
+		return nil
+	}
+
+	switch {
+	case p.match(token.For):
+		return p.forStatement()
+	case p.match(token.If):
+		return p.ifStatement()
+	case p.match(token.Print):
+		return p.printStatement()
+	case p.match(token.Return):
+		return p.returnStatement()
+	case p.match(token.While):
+		return p.whileStatement()
+	case p.match(token.LeftBrace):
+		return stmt.NewBlock(p.block())
+	}
+
+	return p.expressionStatement()
+}
+
+// for loops are desugared into an equivalent while loop wrapped in blocks
+// for the initializer and increment clauses
+func (p *P) forStatement() stmt.Stmt {
+	p.consume(token.LeftParen, "Expect '(' after 'for'")
+
+	var init stmt.Stmt
+	switch {
+	case p.match(token.Semicolon):
+		// no initializer
+	case p.match(token.Var):
+		init = p.varDeclaration()
+	default:
+		init = p.expressionStatement()
+	}
+
+	var cond expr.Expr
+	if !p.check(token.Semicolon) {
+		cond = p.expression()
+	}
+	p.consume(token.Semicolon, "Expect ';' after loop condition")
+
+	var incr expr.Expr
+	if !p.check(token.RightParen) {
+		incr = p.expression()
+	}
+	p.consume(token.RightParen, "Expect ')' after for clauses")
+
+	body := p.statement()
+	if p.Err != nil {
+		return nil
+	}
+
+	if incr != nil {
+		body = stmt.NewBlock([]stmt.Stmt{body, stmt.NewExpression(incr)})
+	}
+
+	if cond == nil {
+		cond = expr.NewLiteral(expr.BooleanLiteral, true)
+	}
+	body = stmt.NewWhile(cond, body)
+
+	if init != nil {
+		body = stmt.NewBlock([]stmt.Stmt{init, body})
+	}
+
+	return body
+}
+
+func (p *P) ifStatement() stmt.Stmt {
+	p.consume(token.LeftParen, "Expect '(' after 'if'")
+	cond := p.expression()
+	p.consume(token.RightParen, "Expect ')' after if condition")
+
+	then := p.statement()
+	var els stmt.Stmt
+	if p.match(token.Else) {
+		els = p.statement()
+	}
+
+	return stmt.NewIf(cond, then, els)
+}
+
+func (p *P) returnStatement() stmt.Stmt {
+	keyword := p.previous()
+
+	var value expr.Expr
+	if !p.check(token.Semicolon) {
+		value = p.expression()
+	}
+	p.consume(token.Semicolon, "Expect ';' after return value")
+
+	return stmt.NewReturn(keyword, value)
+}
+
+func (p *P) whileStatement() stmt.Stmt {
+	p.consume(token.LeftParen, "Expect '(' after 'while'")
+	cond := p.expression()
+	p.consume(token.RightParen, "Expect ')' after condition")
+	body := p.statement()
+
+	return stmt.NewWhile(cond, body)
+}
+
+func (p *P) block() []stmt.Stmt {
+	stmts := make([]stmt.Stmt, 0)
+	for p.Err == nil && !p.check(token.RightBrace) && !p.isAtEnd() {
+		if st := p.declaration(); st != nil {
+			stmts = append(stmts, st)
+		}
+	}
+
+	p.consume(token.RightBrace, "Expect '}' after block")
+
+	return stmts
+}
+
+func (p *P) printStatement() stmt.Stmt {
+	value := p.expression()
+	p.consume(token.Semicolon, "Expect ';' after value")
+
+	return stmt.NewPrint(value)
+}
+
+func (p *P) expressionStatement() stmt.Stmt {
+	ex := p.expression()
+	p.consume(token.Semicolon, "Expect ';' after expression")
+
+	return stmt.NewExpression(ex)
+}
+
 func (p *P) expression() expr.Expr {
 	if p.Err != nil {
 		return nil
@@ -46,22 +356,46 @@ func (p *P) sequenced() expr.Expr {
 		return nil
 	}
 
-	ex := p.ternary()
+	ex := p.assignment()
 
 	for p.match(token.Comma) {
-		right := p.ternary()
+		right := p.assignment()
 		ex = expr.NewSequenced(ex, right)
 	}
 
 	return ex
 }
 
+func (p *P) assignment() expr.Expr {
+	if p.Err != nil {
+		return nil
+	}
+
+	ex := p.ternary()
+
+	if p.match(token.Equal) {
+		equals := p.previous()
+		value := p.assignment()
+
+		switch target := ex.(type) {
+		case *expr.Variable:
+			return expr.NewAssign(target.Name, value)
+		case *expr.Get:
+			return expr.NewSet(target.Object, target.Name, value)
+		}
+
+		p.Err = p.error(equals, errs.CodeInvalidAssignment, "Invalid assignment target")
+	}
+
+	return ex
+}
+
 func (p *P) ternary() expr.Expr {
 	if p.Err != nil {
 		return nil
 	}
 
-	ex := p.equality()
+	ex := p.or()
 
 	if p.match(token.QuestionMark) {
 		pos := p.expression()
@@ -73,6 +407,38 @@ func (p *P) ternary() expr.Expr {
 	return ex
 }
 
+func (p *P) or() expr.Expr {
+	if p.Err != nil {
+		return nil
+	}
+
+	ex := p.and()
+
+	for p.match(token.Or) {
+		op := p.previous()
+		right := p.and()
+		ex = expr.NewLogical(ex, op, right)
+	}
+
+	return ex
+}
+
+func (p *P) and() expr.Expr {
+	if p.Err != nil {
+		return nil
+	}
+
+	ex := p.equality()
+
+	for p.match(token.And) {
+		op := p.previous()
+		right := p.equality()
+		ex = expr.NewLogical(ex, op, right)
+	}
+
+	return ex
+}
+
 func (p *P) equality() expr.Expr {
 	if p.Err != nil {
 		return nil
@@ -128,7 +494,7 @@ func (p *P) multiplication() expr.Expr {
 
 	ex := p.unary()
 
-	for p.match(token.Slash, token.Star) {
+	for p.match(token.Slash, token.Star, token.Percent) {
 		op := p.previous()
 		right := p.unary()
 		ex = expr.NewBinary(ex, op, right)
@@ -142,14 +508,60 @@ func (p *P) unary() expr.Expr {
 		return nil
 	}
 
-	if p.match(token.Bang, token.Bang) {
+	if p.match(token.Bang, token.Minus) {
 		op := p.previous()
 		right := p.unary()
 
 		return expr.NewUnary(op, right)
 	}
 
-	return p.primary()
+	return p.call()
+}
+
+func (p *P) call() expr.Expr {
+	if p.Err != nil {
+		return nil
+	}
+
+	ex := p.primary()
+
+	for p.Err == nil {
+		switch {
+		case p.match(token.LeftParen):
+			ex = p.finishCall(ex)
+		case p.match(token.Dot):
+			name := p.consume(token.Identifier, "Expect property name after '.'")
+			ex = expr.NewGet(ex, name)
+		default:
+			return ex
+		}
+	}
+
+	return nil
+}
+
+func (p *P) finishCall(callee expr.Expr) expr.Expr {
+	args := make([]expr.Expr, 0)
+	if !p.check(token.RightParen) {
+		for {
+			if len(args) >= maxArguments {
+				p.Err = p.error(p.peek(), errs.CodeTooManyArguments, "Cannot have more than 255 arguments")
+				return nil
+			}
+
+			// arguments are parsed above the sequenced level so that commas
+			// separate the arguments
+			args = append(args, p.assignment())
+
+			if !p.match(token.Comma) {
+				break
+			}
+		}
+	}
+
+	paren := p.consume(token.RightParen, "Expect ')' after arguments")
+
+	return expr.NewCall(callee, paren, args)
 }
 
 func (p *P) primary() expr.Expr {
@@ -159,27 +571,91 @@ func (p *P) primary() expr.Expr {
 
 	switch {
 	case p.match(token.False):
-		return expr.NewLiteral(expr.BooleanLiteral, false)
+		return p.literal(expr.BooleanLiteral, false)
 	case p.match(token.True):
-		return expr.NewLiteral(expr.BooleanLiteral, true)
+		return p.literal(expr.BooleanLiteral, true)
 	case p.match(token.Nil):
-		return expr.NewLiteral(expr.NilLiteral, nil)
+		return p.literal(expr.NilLiteral, nil)
 	case p.match(token.Number):
-		return expr.NewLiteral(expr.NumberLiteral, p.previous().Literal)
+		if _, ok := p.previous().Literal.(int64); ok {
+			return p.literal(expr.IntegerLiteral, p.previous().Literal)
+		}
+
+		return p.literal(expr.NumberLiteral, p.previous().Literal)
 	case p.match(token.String):
-		return expr.NewLiteral(expr.StringLiteral, p.previous().Literal)
+		return p.literal(expr.StringLiteral, p.previous().Literal)
+	case p.match(token.Interpolation):
+		return p.interpolation()
+	case p.match(token.Identifier):
+		return expr.NewVariable(p.previous())
+	case p.match(token.This):
+		return expr.NewThis(p.previous())
+	case p.match(token.Super):
+		keyword := p.previous()
+		p.consume(token.Dot, "Expect '.' after 'super'")
+		method := p.consume(token.Identifier, "Expect superclass method name")
+
+		return expr.NewSuper(keyword, method)
+	case p.match(token.Fun):
+		return p.lambda()
 	case p.match(token.LeftParen):
+		left := p.previous()
 		ex := p.expression()
-		p.consume(token.RightParen, "Expect ')' after expression")
+		right := p.consume(token.RightParen, "Expect ')' after expression")
 
-		return expr.NewGrouping(ex)
+		return expr.NewGrouping(left, ex, right)
 	}
 
-	p.Err = parseError(p.peek(), "Expected expression")
+	p.Err = p.error(p.peek(), errs.CodeSyntax, "Expected expression")
 
 	return nil
 }
 
+// interpolation parses the parts of an interpolated string, the first of
+// which has just been matched.
+func (p *P) interpolation() expr.Expr {
+	parts := make([]expr.Expr, 0)
+	for {
+		parts = append(parts, p.literal(expr.StringLiteral, p.previous().Literal))
+		parts = append(parts, p.expression())
+		if !p.match(token.Interpolation) {
+			break
+		}
+	}
+
+	p.consume(token.String, "Expect '}' after interpolated expression")
+	if p.Err != nil {
+		return nil
+	}
+
+	return expr.NewInterpolation(append(parts, p.literal(expr.StringLiteral, p.previous().Literal)))
+}
+
+func (p *P) lambda() expr.Expr {
+	keyword := p.previous()
+	p.consume(token.LeftParen, "Expect '(' after 'fun'")
+	params, body := p.functionBody("function")
+	if p.Err != nil {
+		return nil
+	}
+
+	stmts := make([]interface{}, len(body))
+	for i, st := range body {
+		stmts[i] = st
+	}
+
+	// the block has just consumed the closing brace
+	return expr.NewLambda(keyword, params, stmts, p.previous())
+}
+
+// literal builds a literal expression from the token that was just matched.
+func (p *P) literal(typ expr.LiteralType, value interface{}) expr.Expr {
+	lit := expr.NewLiteral(typ, value)
+	lit.Token = p.previous()
+
+	return lit
+}
+
 // helpers
 
 func (p *P) match(types ...token.Type) bool {
@@ -210,6 +686,24 @@ func (p *P) check(typ token.Type) bool {
 	return p.peek().Type == typ
 }
 
+func (p *P) checkNext(typ token.Type) bool {
+	if p.Err != nil {
+		return false
+	}
+
+	if p.isAtEnd() {
+		return false
+	}
+
+	p.fill(1)
+	next := p.tokens[p.current+1]
+	if next.Type == token.EOF {
+		return false
+	}
+
+	return next.Type == typ
+}
+
 func (p *P) advance() *token.T {
 	if p.Err != nil {
 		return nil
@@ -217,6 +711,7 @@ func (p *P) advance() *token.T {
 
 	if !p.isAtEnd() {
 		p.current++
+		p.discard()
 	}
 
 	return p.previous()
@@ -227,9 +722,30 @@ func (p *P) isAtEnd() bool {
 }
 
 func (p *P) peek() *token.T {
+	p.fill(0)
 	return p.tokens[p.current]
 }
 
+// fill pulls tokens from the source, when streaming, until the token n
+// places after the current one is available.
+func (p *P) fill(n int) {
+	for p.source != nil && len(p.tokens) <= p.current+n {
+		p.tokens = append(p.tokens, p.source.Next())
+	}
+}
+
+// discard drops consumed tokens, when streaming, keeping only the previous
+// token around.
+func (p *P) discard() {
+	if p.source == nil || p.current < streamWindow {
+		return
+	}
+
+	n := p.current - 1
+	p.tokens = append(p.tokens[:0], p.tokens[n:]...)
+	p.current -= n
+}
+
 func (p *P) previous() *token.T {
 	return p.tokens[p.current-1]
 }
@@ -243,7 +759,7 @@ func (p *P) consume(typ token.Type, msg string) *token.T {
 		return p.advance()
 	}
 
-	p.Err = parseError(p.peek(), msg)
+	p.Err = p.error(p.peek(), errs.CodeSyntax, msg)
 
 	return nil
 }
@@ -0,0 +1,111 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/scanner"
+	"github.com/bbuck/glox/tree/parser"
+)
+
+func Test_ParseProgram_ReportsAllErrors(t *testing.T) {
+	reporter := errs.NewCollector()
+	p := newParserWithReporter(`
+		var = 1;
+		print (1;
+		{ var x = ; print 2; }
+		print 3;
+	`, reporter)
+
+	if stmts := p.ParseProgram(); stmts != nil {
+		t.Errorf("expected no statements but got %d", len(stmts))
+	}
+
+	if p.Err != parser.ParseError {
+		t.Errorf("expected Err to be ParseError but got %v", p.Err)
+	}
+
+	lines := []uint{2, 3, 4}
+	perrs := p.Errors()
+	if len(perrs) != len(lines) {
+		t.Fatalf("expected %d errors but got %d", len(lines), len(perrs))
+	}
+
+	for i, line := range lines {
+		if perrs[i].Token.Line != line {
+			t.Errorf("expected error %d on line %d but got line %d", i, line, perrs[i].Token.Line)
+		}
+	}
+
+	if len(reporter.Diagnostics) != len(lines) {
+		t.Errorf("expected %d diagnostics but got %d", len(lines), len(reporter.Diagnostics))
+	}
+}
+
+func Test_Parse_Spans(t *testing.T) {
+	tests := map[string]string{
+		"1 + 2":           "1:1-1:6",
+		"  (a)":           "1:3-1:6",
+		"-x":              "1:1-1:3",
+		"f(1,\n  2)":      "1:1-2:5",
+		"a.b = c ? d : e": "1:1-1:16",
+		"fun () {}":       "1:1-1:10",
+		`"a${b}c" + 1`:    "1:1-1:13",
+	}
+
+	for source, expected := range tests {
+		ex := newParser(source).Parse()
+		if ex == nil {
+			t.Errorf("%q: failed to parse", source)
+			continue
+		}
+
+		if span := ex.Span().String(); span != expected {
+			t.Errorf("%q: expected span %s but got %s", source, expected, span)
+		}
+	}
+}
+
+func Test_Parse_TrailingTokens(t *testing.T) {
+	reporter := errs.NewCollector()
+	p := newParserWithReporter("1 + 2 3", reporter)
+	if ex := p.Parse(); ex != nil {
+		t.Errorf("expected no expression but got %v", ex)
+	}
+
+	if len(reporter.Diagnostics) != 1 || reporter.Diagnostics[0].Span.String() != "1:7-1:8" {
+		t.Errorf("expected a single error at 1:7-1:8 but got %v", reporter.Diagnostics)
+	}
+}
+
+func Test_NewStream(t *testing.T) {
+	source := strings.Repeat("print 1 + 2;\n", 100) + "var = 1;"
+	reporter := errs.NewCollector()
+	p := parser.NewStream(scanner.New(source, reporter), reporter)
+
+	if stmts := p.ParseProgram(); stmts != nil {
+		t.Errorf("expected no statements but got %d", len(stmts))
+	}
+
+	perrs := p.Errors()
+	if len(perrs) != 1 || perrs[0].Token.Line != 101 {
+		t.Errorf("expected a single error on line 101 but got %v", perrs)
+	}
+
+	p = parser.NewStream(scanner.New(source[:len(source)-8], reporter), reporter)
+	if stmts := p.ParseProgram(); len(stmts) != 100 {
+		t.Errorf("expected 100 statements but got %d", len(stmts))
+	}
+}
+
+func newParser(source string) *parser.P {
+	return newParserWithReporter(source, errs.NewCollector())
+}
+
+func newParserWithReporter(source string, reporter errs.Reporter) *parser.P {
+	s := scanner.New(source, reporter)
+	s.ScanTokens()
+
+	return parser.New(s.Tokens(), reporter)
+}
@@ -0,0 +1,190 @@
+package printer
+
+// Graphviz DOT and Mermaid printers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/expr"
+)
+
+// graphNode is a node of the drawn tree and the edges to its children, in
+// order. Edges are labelled where the role of the child isn't clear from
+// its position.
+type graphNode struct {
+	label string
+	edges []graphEdge
+}
+
+type graphEdge struct {
+	to    int
+	label string
+}
+
+type graphBuilder struct {
+	nodes []*graphNode
+}
+
+// PrintDOT will walk the expression tree and print it as a Graphviz DOT
+// graph, with each expression a node labelled with its operator, name or
+// literal value.
+func PrintDOT(e expr.Expr) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("digraph ast {\n  node [shape=box];\n")
+	for i, node := range buildGraph(e) {
+		fmt.Fprintf(buf, "  n%d [label=%s];\n", i, dotQuote(node.label))
+		for _, edge := range node.edges {
+			fmt.Fprintf(buf, "  n%d -> n%d", i, edge.to)
+			if edge.label != "" {
+				fmt.Fprintf(buf, " [label=%s]", dotQuote(edge.label))
+			}
+			buf.WriteString(";\n")
+		}
+	}
+	buf.WriteRune('}')
+
+	return buf.String()
+}
+
+// PrintMermaid will walk the expression tree and print it as a Mermaid
+// flowchart, with each expression a node labelled with its operator, name
+// or literal value.
+func PrintMermaid(e expr.Expr) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("flowchart TD")
+	for i, node := range buildGraph(e) {
+		fmt.Fprintf(buf, "\n  n%d[\"%s\"]", i, mermaidEscape(node.label))
+		for _, edge := range node.edges {
+			fmt.Fprintf(buf, "\n  n%d -->", i)
+			if edge.label != "" {
+				fmt.Fprintf(buf, "|\"%s\"|", mermaidEscape(edge.label))
+			}
+			fmt.Fprintf(buf, " n%d", edge.to)
+		}
+	}
+
+	return buf.String()
+}
+
+// buildGraph returns the nodes of the tree in pre-order, the root first.
+func buildGraph(e expr.Expr) []*graphNode {
+	builder := new(graphBuilder)
+	builder.add(e)
+
+	return builder.nodes
+}
+
+// add adds the node for e, and for all of its children, returning its
+// index.
+func (g *graphBuilder) add(e expr.Expr) int {
+	g.nodes = append(g.nodes, new(graphNode))
+	index := len(g.nodes) - 1
+	e.Accept(g)
+
+	return index
+}
+
+// node labels the node that was just added and adds its children, with
+// the edge labels given in turn.
+func (g *graphBuilder) node(label string, es []expr.Expr, edgeLabels ...string) {
+	node := g.nodes[len(g.nodes)-1]
+	node.label = label
+	for i, e := range es {
+		edge := graphEdge{to: g.add(e)}
+		if i < len(edgeLabels) {
+			edge.label = edgeLabels[i]
+		}
+		node.edges = append(node.edges, edge)
+	}
+}
+
+func (g *graphBuilder) VisitBinary(b *expr.Binary) {
+	g.node(b.Operator.Lexeme, []expr.Expr{b.Left, b.Right})
+}
+
+func (g *graphBuilder) VisitUnary(u *expr.Unary) {
+	g.node(u.Operator.Lexeme, []expr.Expr{u.Right})
+}
+
+func (g *graphBuilder) VisitLiteral(l *expr.Literal) {
+	g.node(literalSource(l), nil)
+}
+
+func (g *graphBuilder) VisitGrouping(gr *expr.Grouping) {
+	g.node("group", []expr.Expr{gr.Expression})
+}
+
+func (g *graphBuilder) VisitSequenced(s *expr.Sequenced) {
+	g.node(",", []expr.Expr{s.Left, s.Right})
+}
+
+func (g *graphBuilder) VisitTernary(t *expr.Ternary) {
+	g.node("?:", []expr.Expr{t.Condition, t.Positive, t.Negative}, "condition", "then", "else")
+}
+
+func (g *graphBuilder) VisitVariable(v *expr.Variable) {
+	g.node(v.Name.Lexeme, nil)
+}
+
+func (g *graphBuilder) VisitAssign(a *expr.Assign) {
+	g.node(a.Name.Lexeme+" =", []expr.Expr{a.Value})
+}
+
+func (g *graphBuilder) VisitLogical(l *expr.Logical) {
+	g.node(l.Operator.Lexeme, []expr.Expr{l.Left, l.Right})
+}
+
+func (g *graphBuilder) VisitCall(c *expr.Call) {
+	labels := []string{"callee"}
+	for i := range c.Arguments {
+		labels = append(labels, fmt.Sprintf("arg %d", i+1))
+	}
+	g.node("call", append([]expr.Expr{c.Callee}, c.Arguments...), labels...)
+}
+
+func (g *graphBuilder) VisitLambda(l *expr.Lambda) {
+	g.node("fun ("+joinLexemes(l.Params)+")", nil)
+}
+
+func (g *graphBuilder) VisitGet(ge *expr.Get) {
+	g.node("."+ge.Name.Lexeme, []expr.Expr{ge.Object})
+}
+
+func (g *graphBuilder) VisitSet(s *expr.Set) {
+	g.node("."+s.Name.Lexeme+" =", []expr.Expr{s.Object, s.Value}, "object", "value")
+}
+
+func (g *graphBuilder) VisitThis(t *expr.This) {
+	g.node("this", nil)
+}
+
+func (g *graphBuilder) VisitSuper(s *expr.Super) {
+	g.node("super."+s.Method.Lexeme, nil)
+}
+
+func (g *graphBuilder) VisitInterpolation(i *expr.Interpolation) {
+	g.node("interpolate", i.Parts)
+}
+
+func joinLexemes(toks []*token.T) string {
+	lexemes := make([]string, len(toks))
+	for i, tok := range toks {
+		lexemes[i] = tok.Lexeme
+	}
+
+	return strings.Join(lexemes, ", ")
+}
+
+// dotQuote quotes s as a DOT string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// mermaidEscape replaces the characters that Mermaid would read as markup
+// in a quoted label with entity codes.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`#`, "#35;", `&`, "#amp;", `"`, "#quot;", `<`, "#lt;", `>`, "#gt;").Replace(s)
+}
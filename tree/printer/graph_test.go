@@ -0,0 +1,65 @@
+package printer_test
+
+import (
+	"testing"
+
+	"github.com/bbuck/glox/tree/printer"
+)
+
+func Test_PrintDOT(t *testing.T) {
+	expect(
+		t,
+		`digraph ast {
+  node [shape=box];
+  n0 [label=","];
+  n0 -> n1;
+  n0 -> n3;
+  n1 [label="a ="];
+  n1 -> n2;
+  n2 [label="1"];
+  n3 [label="?:"];
+  n3 -> n4 [label="condition"];
+  n3 -> n7 [label="then"];
+  n3 -> n8 [label="else"];
+  n4 [label="<"];
+  n4 -> n5;
+  n4 -> n6;
+  n5 [label="a"];
+  n6 [label="2.5"];
+  n7 [label="\"\\\"yes\\\"\""];
+  n8 [label="call"];
+  n8 -> n9 [label="callee"];
+  n8 -> n10 [label="arg 1"];
+  n9 [label="f"];
+  n10 [label="nil"];
+}`,
+		printer.PrintDOT(parse(t, `a = 1, a < 2.5 ? "\"yes\"" : f(nil)`)),
+	)
+}
+
+func Test_PrintMermaid(t *testing.T) {
+	expect(
+		t,
+		`flowchart TD
+  n0["?:"]
+  n0 -->|"condition"| n1
+  n0 -->|"then"| n4
+  n0 -->|"else"| n5
+  n1["#lt;="]
+  n1 --> n2
+  n1 --> n3
+  n2["a"]
+  n3["0"]
+  n4["#quot;#35;#quot;"]
+  n5["-"]
+  n5 --> n6
+  n6["group"]
+  n6 --> n7
+  n7["+"]
+  n7 --> n8
+  n7 --> n9
+  n8["a"]
+  n9["1.0"]`,
+		printer.PrintMermaid(parse(t, `a <= 0 ? "#" : -(a + 1.0)`)),
+	)
+}
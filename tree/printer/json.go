@@ -0,0 +1,464 @@
+package printer
+
+// JSON encoding of syntax trees
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/expr"
+)
+
+// jsonToken is the JSON form of a token. Tokens that were synthesized
+// rather than scanned, and so have no place in the source, are null.
+type jsonToken struct {
+	Type   string       `json:"type"`
+	Lexeme string       `json:"lexeme"`
+	Line   uint         `json:"line"`
+	Start  jsonPosition `json:"start"`
+	End    jsonPosition `json:"end"`
+}
+
+type jsonPosition struct {
+	Line   uint `json:"line"`
+	Column uint `json:"column"`
+	Offset int  `json:"offset"`
+}
+
+// jsonNode is the JSON form of an expression, an object holding its type
+// and fields.
+type jsonNode map[string]interface{}
+
+type jsonEncoder struct {
+	node jsonNode
+	Err  error
+}
+
+// ToJSON encodes the expression tree as JSON. Each expression is an object
+// with a "type" naming the kind of expression, such as "Binary", and a
+// field for each of its children and tokens:
+//
+//	{"type": "Binary", "left": {...}, "operator": {...}, "right": {...}}
+//
+// A token records its type, lexeme and where it was found in the source.
+// A literal records its literal_type, such as "Integer", and its value.
+// Lambdas contain statements, which have no JSON form, and are an error.
+func ToJSON(e expr.Expr) ([]byte, error) {
+	encoder := new(jsonEncoder)
+	node := encoder.encode(e)
+	if encoder.Err != nil {
+		return nil, encoder.Err
+	}
+
+	return json.Marshal(node)
+}
+
+func (j *jsonEncoder) encode(e expr.Expr) jsonNode {
+	if j.Err != nil {
+		return nil
+	}
+
+	j.node = nil
+	e.Accept(j)
+
+	return j.node
+}
+
+func (j *jsonEncoder) encodeAll(es []expr.Expr) []jsonNode {
+	nodes := make([]jsonNode, len(es))
+	for i, e := range es {
+		nodes[i] = j.encode(e)
+	}
+
+	return nodes
+}
+
+func (j *jsonEncoder) VisitBinary(b *expr.Binary) {
+	j.node = jsonNode{
+		"type":     "Binary",
+		"left":     j.encode(b.Left),
+		"operator": tokenJSON(b.Operator),
+		"right":    j.encode(b.Right),
+	}
+}
+
+func (j *jsonEncoder) VisitUnary(u *expr.Unary) {
+	j.node = jsonNode{
+		"type":     "Unary",
+		"operator": tokenJSON(u.Operator),
+		"right":    j.encode(u.Right),
+	}
+}
+
+func (j *jsonEncoder) VisitLiteral(l *expr.Literal) {
+	j.node = jsonNode{
+		"type":         "Literal",
+		"literal_type": l.Type.String(),
+		"value":        l.Value,
+		"token":        tokenJSON(l.Token),
+	}
+}
+
+func (j *jsonEncoder) VisitGrouping(g *expr.Grouping) {
+	j.node = jsonNode{
+		"type":        "Grouping",
+		"left_paren":  tokenJSON(g.LeftParen),
+		"expression":  j.encode(g.Expression),
+		"right_paren": tokenJSON(g.RightParen),
+	}
+}
+
+func (j *jsonEncoder) VisitSequenced(s *expr.Sequenced) {
+	j.node = jsonNode{
+		"type":  "Sequenced",
+		"left":  j.encode(s.Left),
+		"right": j.encode(s.Right),
+	}
+}
+
+func (j *jsonEncoder) VisitTernary(t *expr.Ternary) {
+	j.node = jsonNode{
+		"type":      "Ternary",
+		"condition": j.encode(t.Condition),
+		"positive":  j.encode(t.Positive),
+		"negative":  j.encode(t.Negative),
+	}
+}
+
+func (j *jsonEncoder) VisitVariable(v *expr.Variable) {
+	j.node = jsonNode{
+		"type": "Variable",
+		"name": tokenJSON(v.Name),
+	}
+}
+
+func (j *jsonEncoder) VisitAssign(a *expr.Assign) {
+	j.node = jsonNode{
+		"type":  "Assign",
+		"name":  tokenJSON(a.Name),
+		"value": j.encode(a.Value),
+	}
+}
+
+func (j *jsonEncoder) VisitLogical(l *expr.Logical) {
+	j.node = jsonNode{
+		"type":     "Logical",
+		"left":     j.encode(l.Left),
+		"operator": tokenJSON(l.Operator),
+		"right":    j.encode(l.Right),
+	}
+}
+
+func (j *jsonEncoder) VisitCall(c *expr.Call) {
+	j.node = jsonNode{
+		"type":      "Call",
+		"callee":    j.encode(c.Callee),
+		"paren":     tokenJSON(c.Paren),
+		"arguments": j.encodeAll(c.Arguments),
+	}
+}
+
+func (j *jsonEncoder) VisitLambda(l *expr.Lambda) {
+	j.Err = errors.New("Cannot encode a lambda as JSON.")
+}
+
+func (j *jsonEncoder) VisitGet(g *expr.Get) {
+	j.node = jsonNode{
+		"type":   "Get",
+		"object": j.encode(g.Object),
+		"name":   tokenJSON(g.Name),
+	}
+}
+
+func (j *jsonEncoder) VisitSet(s *expr.Set) {
+	j.node = jsonNode{
+		"type":   "Set",
+		"object": j.encode(s.Object),
+		"name":   tokenJSON(s.Name),
+		"value":  j.encode(s.Value),
+	}
+}
+
+func (j *jsonEncoder) VisitThis(t *expr.This) {
+	j.node = jsonNode{
+		"type":    "This",
+		"keyword": tokenJSON(t.Keyword),
+	}
+}
+
+func (j *jsonEncoder) VisitSuper(s *expr.Super) {
+	j.node = jsonNode{
+		"type":    "Super",
+		"keyword": tokenJSON(s.Keyword),
+		"method":  tokenJSON(s.Method),
+	}
+}
+
+func (j *jsonEncoder) VisitInterpolation(i *expr.Interpolation) {
+	j.node = jsonNode{
+		"type":  "Interpolation",
+		"parts": j.encodeAll(i.Parts),
+	}
+}
+
+func tokenJSON(tok *token.T) *jsonToken {
+	if tok == nil {
+		return nil
+	}
+
+	return &jsonToken{
+		Type:   tok.Type.String(),
+		Lexeme: tok.Lexeme,
+		Line:   tok.Line,
+		Start:  jsonPosition(tok.Start),
+		End:    jsonPosition(tok.End),
+	}
+}
+
+// decoding
+
+// tokenTypes and literalTypes find types by the names they're encoded with.
+var (
+	tokenTypes   = make(map[string]token.Type)
+	literalTypes = make(map[string]expr.LiteralType)
+)
+
+func init() {
+	for typ := token.Type(0); typ <= token.EOF; typ++ {
+		tokenTypes[typ.String()] = typ
+	}
+
+	for typ := expr.StringLiteral; typ <= expr.IntegerLiteral; typ++ {
+		literalTypes[typ.String()] = typ
+	}
+}
+
+type jsonDecoder struct {
+	Err error
+}
+
+// FromJSON decodes an expression tree encoded by ToJSON. The tokens of the
+// tree have all the information ToJSON records, and the tokens of number
+// and string literals also hold their value as their Literal. Only the
+// tokens of literals and the parentheses of groupings and calls may be
+// null, any other missing token is an error.
+func FromJSON(data []byte) (expr.Expr, error) {
+	decoder := new(jsonDecoder)
+	e := decoder.decode(data)
+	if decoder.Err != nil {
+		return nil, decoder.Err
+	}
+
+	return e, nil
+}
+
+// decode decodes a single expression, which may not be null.
+func (d *jsonDecoder) decode(data json.RawMessage) expr.Expr {
+	var fields map[string]json.RawMessage
+	if d.Err != nil || !d.unmarshal(data, &fields) {
+		return nil
+	}
+
+	if fields == nil {
+		d.Err = errors.New("Expect an expression but found null.")
+		return nil
+	}
+
+	var typ string
+	d.unmarshal(fields["type"], &typ)
+	if d.Err != nil {
+		return nil
+	}
+
+	child := func(name string) expr.Expr {
+		if _, ok := fields[name]; !ok && d.Err == nil {
+			d.Err = fmt.Errorf("Expect %q in %s expression.", name, typ)
+		}
+
+		return d.decode(fields[name])
+	}
+
+	required := func(name string) *token.T {
+		tok := d.token(fields[name])
+		if tok == nil && d.Err == nil {
+			d.Err = fmt.Errorf("Expect %q in %s expression.", name, typ)
+		}
+
+		return tok
+	}
+
+	var e expr.Expr
+	switch typ {
+	case "Binary":
+		e = expr.NewBinary(child("left"), required("operator"), child("right"))
+	case "Unary":
+		e = expr.NewUnary(required("operator"), child("right"))
+	case "Literal":
+		e = d.literal(fields)
+	case "Grouping":
+		e = expr.NewGrouping(d.token(fields["left_paren"]), child("expression"), d.token(fields["right_paren"]))
+	case "Sequenced":
+		e = expr.NewSequenced(child("left"), child("right"))
+	case "Ternary":
+		e = expr.NewTernary(child("condition"), child("positive"), child("negative"))
+	case "Variable":
+		e = expr.NewVariable(required("name"))
+	case "Assign":
+		e = expr.NewAssign(required("name"), child("value"))
+	case "Logical":
+		e = expr.NewLogical(child("left"), required("operator"), child("right"))
+	case "Call":
+		e = expr.NewCall(child("callee"), d.token(fields["paren"]), d.decodeAll(fields["arguments"]))
+	case "Get":
+		e = expr.NewGet(child("object"), required("name"))
+	case "Set":
+		e = expr.NewSet(child("object"), required("name"), child("value"))
+	case "This":
+		e = expr.NewThis(required("keyword"))
+	case "Super":
+		e = expr.NewSuper(required("keyword"), required("method"))
+	case "Interpolation":
+		e = d.interpolation(d.decodeAll(fields["parts"]))
+	default:
+		d.Err = fmt.Errorf("Unknown expression type %q.", typ)
+	}
+
+	if d.Err != nil {
+		return nil
+	}
+
+	return e
+}
+
+func (d *jsonDecoder) decodeAll(data json.RawMessage) []expr.Expr {
+	var nodes []json.RawMessage
+	d.unmarshal(data, &nodes)
+
+	es := make([]expr.Expr, len(nodes))
+	for i, node := range nodes {
+		es[i] = d.decode(node)
+	}
+
+	return es
+}
+
+// interpolation checks the parts of an interpolated string alternate
+// between string literals and the expressions embedded between them,
+// starting and ending with a string.
+func (d *jsonDecoder) interpolation(parts []expr.Expr) expr.Expr {
+	if d.Err != nil {
+		return nil
+	}
+
+	if len(parts)%2 == 0 {
+		d.Err = errors.New("Expect an odd number of parts in Interpolation expression.")
+		return nil
+	}
+
+	for i := 0; i < len(parts); i += 2 {
+		if lit, ok := parts[i].(*expr.Literal); !ok || lit.Type != expr.StringLiteral {
+			d.Err = fmt.Errorf("Expect a string literal as part %d of Interpolation expression.", i+1)
+			return nil
+		}
+	}
+
+	return expr.NewInterpolation(parts)
+}
+
+// literal decodes the value of a literal as the Go type its literal type
+// calls for, integers are kept exact.
+func (d *jsonDecoder) literal(fields map[string]json.RawMessage) expr.Expr {
+	var name string
+	d.unmarshal(fields["literal_type"], &name)
+	typ, ok := literalTypes[name]
+	if !ok && d.Err == nil {
+		d.Err = fmt.Errorf("Unknown literal type %q.", name)
+	}
+
+	var value interface{}
+	switch typ {
+	case expr.IntegerLiteral, expr.NumberLiteral:
+		var number json.Number
+		if d.unmarshal(fields["value"], &number) {
+			if typ == expr.IntegerLiteral {
+				value, d.Err = strconv.ParseInt(number.String(), 10, 64)
+			} else {
+				value, d.Err = strconv.ParseFloat(number.String(), 64)
+			}
+		}
+	case expr.StringLiteral:
+		var s string
+		d.unmarshal(fields["value"], &s)
+		value = s
+	case expr.BooleanLiteral:
+		var b bool
+		d.unmarshal(fields["value"], &b)
+		value = b
+	default:
+		// nil and keyword literals have no value
+		if d.unmarshal(fields["value"], &value) && value != nil {
+			d.Err = fmt.Errorf("Expect null value in %s literal.", name)
+		}
+	}
+
+	lit := expr.NewLiteral(typ, value)
+	lit.Token = d.token(fields["token"])
+	if lit.Token != nil && typ != expr.BooleanLiteral && typ != expr.NilLiteral {
+		lit.Token.Literal = value
+	}
+
+	return lit
+}
+
+// token decodes a token, which is nil if it's null or missing. Whether the
+// token is required is up to the caller.
+func (d *jsonDecoder) token(data json.RawMessage) *token.T {
+	var jt *jsonToken
+	if data == nil || !d.unmarshal(data, &jt) || jt == nil {
+		return nil
+	}
+
+	typ, ok := tokenTypes[jt.Type]
+	if !ok {
+		d.Err = fmt.Errorf("Unknown token type %q.", jt.Type)
+		return nil
+	}
+
+	tok := token.New(typ, jt.Lexeme, nil, jt.Line)
+	tok.Start = token.Position(jt.Start)
+	tok.End = token.Position(jt.End)
+
+	return tok
+}
+
+// unmarshal decodes the JSON into v, numbers are decoded as json.Number.
+// It returns false if the JSON is missing or invalid, or if anything but
+// whitespace follows the value.
+func (d *jsonDecoder) unmarshal(data json.RawMessage, v interface{}) bool {
+	if d.Err != nil {
+		return false
+	}
+
+	if data == nil {
+		d.Err = errors.New("Unexpected end of JSON input.")
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if d.Err = decoder.Decode(v); d.Err != nil {
+		return false
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		d.Err = errors.New("Unexpected data after JSON value.")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,127 @@
+package printer_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/scanner"
+	"github.com/bbuck/glox/tree/expr"
+	"github.com/bbuck/glox/tree/parser"
+	"github.com/bbuck/glox/tree/printer"
+)
+
+func Test_ToJSON(t *testing.T) {
+	data, err := printer.ToJSON(parse(t, "-(1 + 2.5)"))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	expect(
+		t,
+		`{"operator":{"type":"Minus","lexeme":"-","line":1,"start":{"line":1,"column":1,"offset":0},"end":{"line":1,"column":2,"offset":1}},`+
+			`"right":{"expression":{"left":{"literal_type":"Integer","token":{"type":"Number","lexeme":"1","line":1,"start":{"line":1,"column":3,"offset":2},"end":{"line":1,"column":4,"offset":3}},"type":"Literal","value":1},`+
+			`"operator":{"type":"Plus","lexeme":"+","line":1,"start":{"line":1,"column":5,"offset":4},"end":{"line":1,"column":6,"offset":5}},`+
+			`"right":{"literal_type":"Number","token":{"type":"Number","lexeme":"2.5","line":1,"start":{"line":1,"column":7,"offset":6},"end":{"line":1,"column":10,"offset":9}},"type":"Literal","value":2.5},"type":"Binary"},`+
+			`"left_paren":{"type":"LeftParen","lexeme":"(","line":1,"start":{"line":1,"column":2,"offset":1},"end":{"line":1,"column":3,"offset":2}},`+
+			`"right_paren":{"type":"RightParen","lexeme":")","line":1,"start":{"line":1,"column":10,"offset":9},"end":{"line":1,"column":11,"offset":10}},"type":"Grouping"},"type":"Unary"}`,
+		string(data),
+	)
+}
+
+func Test_JSON_RoundTrip(t *testing.T) {
+	sources := []string{
+		"1 + 2 * 3 - 4 / 5 % 6",
+		"(1, 2.0), 3 ? nil : true == !false",
+		"9223372036854775807 + 1e300 + 0.1",
+		`"a\n${b + "c"}d" != "${1}"`,
+		"a = b.c = d(1, e)(f).g",
+		"this.x and super.y or z",
+	}
+
+	for _, source := range sources {
+		e := parse(t, source)
+		data, err := printer.ToJSON(e)
+		if err != nil {
+			t.Errorf("%q: unexpected error encoding %s", source, err)
+			continue
+		}
+
+		decoded, err := printer.FromJSON(data)
+		if err != nil {
+			t.Errorf("%q: unexpected error decoding %s", source, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(e, decoded) {
+			t.Errorf("%q: expected %s but decoded %s", source, printer.Print(e), printer.Print(decoded))
+		}
+	}
+}
+
+func Test_JSON_Errors(t *testing.T) {
+	if _, err := printer.ToJSON(parse(t, "fun () {}")); err == nil {
+		t.Errorf("expected an error encoding a lambda but got none")
+	}
+
+	tests := map[string]string{
+		`null`:               "Expect an expression but found null.",
+		`{"type": "Lambda"}`: `Unknown expression type "Lambda".`,
+		`{"type": "Sequenced", "left": {"type": "Literal", "literal_type": "Nil", "value": null}}`:                                                                  `Expect "right" in Sequenced expression.`,
+		`{"type": "Binary", "left": {"type": "Literal", "literal_type": "Nil", "value": null}, "right": {"type": "Literal", "literal_type": "Nil", "value": null}}`: `Expect "operator" in Binary expression.`,
+		`{"type": "Variable", "name": null}`:                                                                    `Expect "name" in Variable expression.`,
+		`{"type": "Literal", "literal_type": "Float", "value": 1}`:                                              `Unknown literal type "Float".`,
+		`{"type": "Literal", "literal_type": "Integer", "value": 1.5}`:                                          `strconv.ParseInt: parsing "1.5": invalid syntax`,
+		`{"type": "Variable", "name": {"type": "Word"}}`:                                                        `Unknown token type "Word".`,
+		`{"type": "Literal", "literal_type": "Nil", "value": {"a": 1}}`:                                         "Expect null value in Nil literal.",
+		`{"type": "Literal", "literal_type": "Keyword", "value": "x"}`:                                          "Expect null value in Keyword literal.",
+		`{"type": "Literal", "literal_type": "Nil", "value": null} {}`:                                          "Unexpected data after JSON value.",
+		`{"type": "Literal", "literal_type": "Nil", "value": null} junk`:                                        "Unexpected data after JSON value.",
+		`{"type": "Interpolation", "parts": []}`:                                                                "Expect an odd number of parts in Interpolation expression.",
+		`{"type": "Interpolation", "parts": [{"type": "This", "keyword": {"type": "This", "lexeme": "this"}}]}`: "Expect a string literal as part 1 of Interpolation expression.",
+	}
+
+	for data, expected := range tests {
+		_, err := printer.FromJSON([]byte(data))
+		if err == nil {
+			t.Errorf("%s: expected an error but got none", data)
+			continue
+		}
+
+		expect(t, expected, err.Error())
+	}
+}
+
+// Test_FromJSON_OptionalTokens decodes trees without the tokens that may be
+// null, which must still be printable.
+func Test_FromJSON_OptionalTokens(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "Grouping", "left_paren": null, "expression": {"type": "Literal", "literal_type": "Nil", "value": null}, "right_paren": null}`: "nil",
+		`{"type": "Call", "callee": {"type": "Variable", "name": {"type": "Identifier", "lexeme": "f"}}, "paren": null, "arguments": []}`:        "f()",
+	}
+
+	for data, expected := range tests {
+		e, err := printer.FromJSON([]byte(data))
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", data, err)
+			continue
+		}
+
+		if e.Span().IsValid() {
+			t.Errorf("%s: expected an invalid span but got %v", data, e.Span())
+		}
+
+		expect(t, expected, printer.PrintSource(e))
+	}
+}
+
+func parse(t *testing.T, source string) expr.Expr {
+	s := scanner.New(source, errs.NewCollector())
+	s.ScanTokens()
+	ex := parser.New(s.Tokens(), errs.NewCollector()).Parse()
+	if ex == nil {
+		t.Fatalf("%q: failed to parse", source)
+	}
+
+	return ex
+}
@@ -3,6 +3,7 @@ package printer
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 
 	"github.com/bbuck/glox/tree/expr"
 )
@@ -53,6 +54,60 @@ func (p *astPrinter) VisitTernary(t *expr.Ternary) {
 	p.parenthesize("if", t.Condition, t.Positive, t.Negative)
 }
 
+func (p *astPrinter) VisitVariable(v *expr.Variable) {
+	p.buf.WriteString(v.Name.Lexeme)
+}
+
+func (p *astPrinter) VisitAssign(a *expr.Assign) {
+	p.parenthesize("= "+a.Name.Lexeme, a.Value)
+}
+
+func (p *astPrinter) VisitLogical(l *expr.Logical) {
+	p.parenthesize(l.Operator.Lexeme, l.Left, l.Right)
+}
+
+func (p *astPrinter) VisitCall(c *expr.Call) {
+	p.parenthesize("call", append([]expr.Expr{c.Callee}, c.Arguments...)...)
+}
+
+func (p *astPrinter) VisitLambda(l *expr.Lambda) {
+	p.buf.WriteString("(fun (")
+	for i, param := range l.Params {
+		if i > 0 {
+			p.buf.WriteRune(' ')
+		}
+		p.buf.WriteString(param.Lexeme)
+	}
+	p.buf.WriteString("))")
+}
+
+func (p *astPrinter) VisitGet(g *expr.Get) {
+	p.parenthesize("get "+g.Name.Lexeme, g.Object)
+}
+
+func (p *astPrinter) VisitSet(s *expr.Set) {
+	p.parenthesize("set "+s.Name.Lexeme, s.Object, s.Value)
+}
+
+func (p *astPrinter) VisitThis(t *expr.This) {
+	p.buf.WriteString("this")
+}
+
+func (p *astPrinter) VisitSuper(s *expr.Super) {
+	p.buf.WriteString("super." + s.Method.Lexeme)
+}
+
+func (p *astPrinter) VisitInterpolation(i *expr.Interpolation) {
+	p.buf.WriteString("(interpolate")
+	for _, part := range i.Parts {
+		p.buf.WriteRune(' ')
+		if !writeStringLiteral(p.buf, part) {
+			part.Accept(p)
+		}
+	}
+	p.buf.WriteRune(')')
+}
+
 func (p *astPrinter) parenthesize(name string, es ...expr.Expr) {
 	p.buf.WriteRune('(')
 	p.buf.WriteString(name)
@@ -62,3 +117,17 @@ func (p *astPrinter) parenthesize(name string, es ...expr.Expr) {
 	}
 	p.buf.WriteRune(')')
 }
+
+// writeStringLiteral writes e quoted if it's a string literal, so strings
+// can be told apart from the expressions and instructions around them. It
+// returns false, having written nothing, for any other expression.
+func writeStringLiteral(buf *bytes.Buffer, e expr.Expr) bool {
+	lit, ok := e.(*expr.Literal)
+	if !ok || lit.Type != expr.StringLiteral {
+		return false
+	}
+
+	buf.WriteString(strconv.Quote(lit.Value.(string)))
+
+	return true
+}
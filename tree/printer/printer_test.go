@@ -38,12 +38,22 @@ var (
 		),
 		ex,
 	)
+
+	iex = expr.NewInterpolation([]expr.Expr{
+		str("sum: "),
+		ex,
+		str("\n"),
+	})
 )
 
 func number(n float64) *expr.Literal {
 	return expr.NewLiteral(expr.NumberLiteral, n)
 }
 
+func str(s string) *expr.Literal {
+	return expr.NewLiteral(expr.StringLiteral, s)
+}
+
 func Test_Print(t *testing.T) {
 	expect(
 		t,
@@ -63,7 +73,7 @@ func Test_Print_Ternary(t *testing.T) {
 func Test_PrintRPN(t *testing.T) {
 	expect(
 		t,
-		"8 10 + 8 - *",
+		"8.0 10.0 + 8.0 neg *",
 		printer.PrintRPN(ex),
 	)
 }
@@ -71,11 +81,54 @@ func Test_PrintRPN(t *testing.T) {
 func Test_PrintRPN_Ternary(t *testing.T) {
 	expect(
 		t,
-		"88 44 - ? 1 - : 8 10 + 8 - * ;",
+		"88.0 44.0 - jf:L0 1.0 neg jmp:L1 L0: 8.0 10.0 + 8.0 neg * L1:",
 		printer.PrintRPN(tex),
 	)
 }
 
+func Test_PrintRPN_Logical(t *testing.T) {
+	expect(
+		t,
+		`load:a pop load:a dup jf:L1 pop "b" L1: dup jt:L0 pop nil L0:`,
+		printer.PrintRPN(expr.NewSequenced(
+			variable("a"),
+			expr.NewLogical(
+				expr.NewLogical(variable("a"), token.New(token.And, "and", nil, 1), str("b")),
+				token.New(token.Or, "or", nil, 1),
+				expr.NewLiteral(expr.NilLiteral, nil),
+			),
+		)),
+	)
+}
+
+func Test_Print_Integer(t *testing.T) {
+	expect(
+		t,
+		"(% 9223372036854775807 2.5)",
+		printer.Print(expr.NewBinary(
+			expr.NewLiteral(expr.IntegerLiteral, int64(9223372036854775807)),
+			token.New(token.Percent, "%", nil, 1),
+			number(2.5),
+		)),
+	)
+}
+
+func Test_Print_Interpolation(t *testing.T) {
+	expect(
+		t,
+		`(interpolate "sum: " (* (+ 8 10) (- 8)) "\n")`,
+		printer.Print(iex),
+	)
+}
+
+func Test_PrintRPN_Interpolation(t *testing.T) {
+	expect(
+		t,
+		`"sum: " 8.0 10.0 + 8.0 neg * "\n" interpolate:3`,
+		printer.PrintRPN(iex),
+	)
+}
+
 func expect(t *testing.T, expected, result string) {
 	if result != expected {
 		t.Errorf("expected %q but got %q", expected, result)
@@ -6,15 +6,37 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/bbuck/glox/token"
 	"github.com/bbuck/glox/tree/expr"
 )
 
 type rpnPrinter struct {
-	buf *bytes.Buffer
+	buf    *bytes.Buffer
+	labels int
 }
 
 // PrintRPN will walk the expression tree and print the operations in
-// reverse polish notation (with the operation after the numbers)
+// reverse polish notation (with the operation after the numbers), as a
+// program for a stack machine. Instructions are separated by spaces and run
+// in order:
+//
+//	42 1.5 "str" true nil  push a value, floats always have a fraction
+//	                       or exponent and strings are quoted as in Go
+//	+ - * / % == != < <= > >=
+//	                       pop two values and push the result
+//	neg !                  pop a value and push its negation
+//	dup                    push a copy of the top value
+//	pop                    discard the top value
+//	L0:                    a label, which does nothing
+//	jmp:L0                 continue from the label
+//	jf:L0 jt:L0            pop a value and continue from the label if it
+//	                       was falsey or truthy
+//	interpolate:3          pop 3 values and push their printed values joined
+//
+// Ternaries and the logical operators are written with jumps so that only
+// the operands they evaluate are run. Variables, calls and the other
+// expressions that need an environment are written as load:a, store:a,
+// call:2, get:a, set:a, this, super:a and <fn>.
 func PrintRPN(e expr.Expr) string {
 	printer := &rpnPrinter{
 		buf: new(bytes.Buffer),
@@ -29,16 +51,18 @@ func (p *rpnPrinter) VisitBinary(b *expr.Binary) {
 }
 
 func (p *rpnPrinter) VisitUnary(u *expr.Unary) {
+	if u.Operator.Type == token.Minus {
+		p.notate("neg", u.Right)
+		return
+	}
+
 	p.notate(u.Operator.Lexeme, u.Right)
 }
 
 func (p *rpnPrinter) VisitLiteral(l *expr.Literal) {
-	if l.Value == nil {
-		p.buf.WriteString("nil")
-		return
+	if !writeStringLiteral(p.buf, l) {
+		p.buf.WriteString(literalSource(l))
 	}
-
-	p.buf.WriteString(fmt.Sprintf("%v", l.Value))
 }
 
 func (p *rpnPrinter) VisitGrouping(g *expr.Grouping) {
@@ -46,17 +70,70 @@ func (p *rpnPrinter) VisitGrouping(g *expr.Grouping) {
 }
 
 func (p *rpnPrinter) VisitSequenced(s *expr.Sequenced) {
-	s.Left.Accept(p)
-	p.buf.WriteString(" -> ")
+	p.notate("pop", s.Left)
+	p.buf.WriteRune(' ')
 	s.Right.Accept(p)
 }
 
 func (p *rpnPrinter) VisitTernary(t *expr.Ternary) {
-	p.notate("?", t.Condition)
+	els, end := p.label(), p.label()
+	p.notate("jf:"+els, t.Condition)
 	p.buf.WriteRune(' ')
-	p.notate(":", t.Positive)
+	p.notate("jmp:"+end, t.Positive)
+	p.buf.WriteString(" " + els + ": ")
+	p.notate(end+":", t.Negative)
+}
+
+func (p *rpnPrinter) VisitVariable(v *expr.Variable) {
+	p.buf.WriteString("load:" + v.Name.Lexeme)
+}
+
+func (p *rpnPrinter) VisitAssign(a *expr.Assign) {
+	p.notate("store:"+a.Name.Lexeme, a.Value)
+}
+
+// VisitLogical keeps the left operand as the result, jumping past the right
+// operand, when it decides the result.
+func (p *rpnPrinter) VisitLogical(l *expr.Logical) {
+	jump := "jf:"
+	if l.Operator.Type == token.Or {
+		jump = "jt:"
+	}
+
+	end := p.label()
+	p.notate("dup "+jump+end+" pop", l.Left)
 	p.buf.WriteRune(' ')
-	p.notate(";", t.Negative)
+	p.notate(end+":", l.Right)
+}
+
+func (p *rpnPrinter) VisitCall(c *expr.Call) {
+	es := make([]expr.Expr, 0, len(c.Arguments)+1)
+	es = append(es, c.Callee)
+	p.notate(fmt.Sprintf("call:%d", len(c.Arguments)), append(es, c.Arguments...)...)
+}
+
+func (p *rpnPrinter) VisitLambda(l *expr.Lambda) {
+	p.buf.WriteString("<fn>")
+}
+
+func (p *rpnPrinter) VisitGet(g *expr.Get) {
+	p.notate("get:"+g.Name.Lexeme, g.Object)
+}
+
+func (p *rpnPrinter) VisitSet(s *expr.Set) {
+	p.notate("set:"+s.Name.Lexeme, s.Object, s.Value)
+}
+
+func (p *rpnPrinter) VisitThis(t *expr.This) {
+	p.buf.WriteString("this")
+}
+
+func (p *rpnPrinter) VisitSuper(s *expr.Super) {
+	p.buf.WriteString("super:" + s.Method.Lexeme)
+}
+
+func (p *rpnPrinter) VisitInterpolation(i *expr.Interpolation) {
+	p.notate(fmt.Sprintf("interpolate:%d", len(i.Parts)), i.Parts...)
 }
 
 func (p *rpnPrinter) notate(name string, es ...expr.Expr) {
@@ -66,3 +143,10 @@ func (p *rpnPrinter) notate(name string, es ...expr.Expr) {
 	}
 	p.buf.WriteString(name)
 }
+
+// label returns a new label to jump to, unique within the program.
+func (p *rpnPrinter) label() string {
+	p.labels++
+
+	return fmt.Sprintf("L%d", p.labels-1)
+}
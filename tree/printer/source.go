@@ -0,0 +1,383 @@
+package printer
+
+// Lox source printer
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/expr"
+	"github.com/bbuck/glox/tree/stmt"
+)
+
+// The precedence of each level of the grammar, from the loosest binding to
+// the tightest.
+const (
+	precSequenced = iota
+	precAssignment
+	precTernary
+	precOr
+	precAnd
+	precEquality
+	precComparison
+	precAddition
+	precMultiplication
+	precUnary
+	precCall
+	precPrimary
+)
+
+// operatorPrecedence is the precedence of each binary and logical operator.
+var operatorPrecedence = map[token.Type]int{
+	token.Or:           precOr,
+	token.And:          precAnd,
+	token.BangEqual:    precEquality,
+	token.EqualEqual:   precEquality,
+	token.Greater:      precComparison,
+	token.GreaterEqual: precComparison,
+	token.Less:         precComparison,
+	token.LessEqual:    precComparison,
+	token.Minus:        precAddition,
+	token.Plus:         precAddition,
+	token.Slash:        precMultiplication,
+	token.Star:         precMultiplication,
+	token.Percent:      precMultiplication,
+}
+
+type sourcePrinter struct {
+	buf *bytes.Buffer
+}
+
+// PrintSource will walk the expression tree and print it as Lox source
+// that parses back to the same tree. Parentheses are only printed where the
+// precedence and associativity of the grammar require them, so Grouping
+// expressions don't print their own parentheses.
+func PrintSource(e expr.Expr) string {
+	printer := &sourcePrinter{
+		buf: new(bytes.Buffer),
+	}
+	printer.expr(e, precSequenced, true)
+
+	return printer.buf.String()
+}
+
+// expr prints e, in parentheses if it binds more loosely than min. If open
+// is false then e is followed by a comma that would be swallowed by the
+// trailing expression of a ternary, so e must not end in one.
+func (p *sourcePrinter) expr(e expr.Expr, min int, open bool) {
+	if precedence(e) < min || (!open && endsOpen(e)) {
+		p.buf.WriteRune('(')
+		e.Accept(p)
+		p.buf.WriteRune(')')
+		return
+	}
+
+	e.Accept(p)
+}
+
+func (p *sourcePrinter) VisitBinary(b *expr.Binary) {
+	p.infix(b.Left, b.Operator, b.Right)
+}
+
+func (p *sourcePrinter) VisitLogical(l *expr.Logical) {
+	p.infix(l.Left, l.Operator, l.Right)
+}
+
+// infix prints a left associative binary operator.
+func (p *sourcePrinter) infix(left expr.Expr, op *token.T, right expr.Expr) {
+	prec := operatorPrecedence[op.Type]
+	p.expr(left, prec, true)
+	p.buf.WriteString(" " + op.Lexeme + " ")
+	p.expr(right, prec+1, true)
+}
+
+func (p *sourcePrinter) VisitUnary(u *expr.Unary) {
+	p.buf.WriteString(u.Operator.Lexeme)
+	p.expr(u.Right, precUnary, true)
+}
+
+func (p *sourcePrinter) VisitLiteral(l *expr.Literal) {
+	p.buf.WriteString(literalSource(l))
+}
+
+func (p *sourcePrinter) VisitGrouping(g *expr.Grouping) {
+	g.Expression.Accept(p)
+}
+
+func (p *sourcePrinter) VisitSequenced(s *expr.Sequenced) {
+	p.expr(s.Left, precSequenced, false)
+	p.buf.WriteString(", ")
+	p.expr(s.Right, precAssignment, true)
+}
+
+func (p *sourcePrinter) VisitTernary(t *expr.Ternary) {
+	p.expr(t.Condition, precOr, true)
+	p.buf.WriteString(" ? ")
+	p.expr(t.Positive, precSequenced, true)
+	p.buf.WriteString(" : ")
+	p.expr(t.Negative, precSequenced, true)
+}
+
+func (p *sourcePrinter) VisitVariable(v *expr.Variable) {
+	p.buf.WriteString(v.Name.Lexeme)
+}
+
+func (p *sourcePrinter) VisitAssign(a *expr.Assign) {
+	p.buf.WriteString(a.Name.Lexeme + " = ")
+	p.expr(a.Value, precAssignment, true)
+}
+
+func (p *sourcePrinter) VisitCall(c *expr.Call) {
+	p.expr(c.Callee, precCall, true)
+	p.buf.WriteRune('(')
+	for i, arg := range c.Arguments {
+		if i > 0 {
+			p.buf.WriteString(", ")
+		}
+		p.expr(arg, precAssignment, i == len(c.Arguments)-1)
+	}
+	p.buf.WriteRune(')')
+}
+
+func (p *sourcePrinter) VisitLambda(l *expr.Lambda) {
+	p.buf.WriteString("fun ")
+	body := make([]stmt.Stmt, len(l.Body))
+	for i, st := range l.Body {
+		body[i] = st.(stmt.Stmt)
+	}
+	p.function(l.Params, body)
+}
+
+func (p *sourcePrinter) VisitGet(g *expr.Get) {
+	p.expr(g.Object, precCall, true)
+	p.buf.WriteString("." + g.Name.Lexeme)
+}
+
+func (p *sourcePrinter) VisitSet(s *expr.Set) {
+	p.expr(s.Object, precCall, true)
+	p.buf.WriteString("." + s.Name.Lexeme + " = ")
+	p.expr(s.Value, precAssignment, true)
+}
+
+func (p *sourcePrinter) VisitThis(t *expr.This) {
+	p.buf.WriteString("this")
+}
+
+func (p *sourcePrinter) VisitSuper(s *expr.Super) {
+	p.buf.WriteString("super." + s.Method.Lexeme)
+}
+
+func (p *sourcePrinter) VisitInterpolation(i *expr.Interpolation) {
+	p.buf.WriteRune('"')
+	for n, part := range i.Parts {
+		if n%2 == 0 {
+			p.buf.WriteString(escapeString(part.(*expr.Literal).Value.(string)))
+			continue
+		}
+
+		p.buf.WriteString("${")
+		p.expr(part, precSequenced, true)
+		p.buf.WriteRune('}')
+	}
+	p.buf.WriteRune('"')
+}
+
+// statements, which are only found in the bodies of functions
+
+func (p *sourcePrinter) VisitExpression(e *stmt.Expression) {
+	// a statement beginning with "fun" would be a function declaration
+	start := p.buf.Len()
+	p.expr(e.Expression, precSequenced, true)
+	if strings.HasPrefix(p.buf.String()[start:], "fun (") {
+		text := p.buf.String()[start:]
+		p.buf.Truncate(start)
+		p.buf.WriteString("(" + text + ")")
+	}
+	p.buf.WriteRune(';')
+}
+
+func (p *sourcePrinter) VisitPrint(pr *stmt.Print) {
+	p.buf.WriteString("print ")
+	p.expr(pr.Expression, precSequenced, true)
+	p.buf.WriteRune(';')
+}
+
+func (p *sourcePrinter) VisitVar(v *stmt.Var) {
+	p.buf.WriteString("var " + v.Name.Lexeme)
+	if v.Initializer != nil {
+		p.buf.WriteString(" = ")
+		p.expr(v.Initializer, precSequenced, true)
+	}
+	p.buf.WriteRune(';')
+}
+
+func (p *sourcePrinter) VisitBlock(b *stmt.Block) {
+	p.block(b.Statements)
+}
+
+func (p *sourcePrinter) VisitIf(i *stmt.If) {
+	p.buf.WriteString("if (")
+	p.expr(i.Condition, precSequenced, true)
+	p.buf.WriteString(") ")
+	i.Then.Accept(p)
+	if i.Else != nil {
+		p.buf.WriteString(" else ")
+		i.Else.Accept(p)
+	}
+}
+
+func (p *sourcePrinter) VisitWhile(w *stmt.While) {
+	p.buf.WriteString("while (")
+	p.expr(w.Condition, precSequenced, true)
+	p.buf.WriteString(") ")
+	w.Body.Accept(p)
+}
+
+func (p *sourcePrinter) VisitFunction(f *stmt.Function) {
+	p.buf.WriteString("fun " + f.Name.Lexeme)
+	p.function(f.Params, f.Body)
+}
+
+func (p *sourcePrinter) VisitReturn(r *stmt.Return) {
+	p.buf.WriteString("return")
+	if r.Value != nil {
+		p.buf.WriteRune(' ')
+		p.expr(r.Value, precSequenced, true)
+	}
+	p.buf.WriteRune(';')
+}
+
+func (p *sourcePrinter) VisitClass(c *stmt.Class) {
+	p.buf.WriteString("class " + c.Name.Lexeme)
+	if c.Superclass != nil {
+		p.buf.WriteString(" < " + c.Superclass.Name.Lexeme)
+	}
+	p.buf.WriteString(" {")
+	for _, m := range c.Methods {
+		p.buf.WriteString(" " + m.Name.Lexeme)
+		p.function(m.Params, m.Body)
+	}
+	p.buf.WriteString(" }")
+}
+
+// function prints the parameters and body of a function.
+func (p *sourcePrinter) function(params []*token.T, body []stmt.Stmt) {
+	p.buf.WriteRune('(')
+	for i, param := range params {
+		if i > 0 {
+			p.buf.WriteString(", ")
+		}
+		p.buf.WriteString(param.Lexeme)
+	}
+	p.buf.WriteString(") ")
+	p.block(body)
+}
+
+func (p *sourcePrinter) block(stmts []stmt.Stmt) {
+	p.buf.WriteRune('{')
+	for _, st := range stmts {
+		p.buf.WriteRune(' ')
+		st.Accept(p)
+	}
+	p.buf.WriteString(" }")
+}
+
+// precedence returns how tightly e binds, the level of the grammar it's
+// parsed at.
+func precedence(e expr.Expr) int {
+	switch e := e.(type) {
+	case *expr.Sequenced:
+		return precSequenced
+	case *expr.Assign, *expr.Set:
+		return precAssignment
+	case *expr.Ternary:
+		return precTernary
+	case *expr.Binary:
+		return operatorPrecedence[e.Operator.Type]
+	case *expr.Logical:
+		return operatorPrecedence[e.Operator.Type]
+	case *expr.Unary:
+		return precUnary
+	case *expr.Call, *expr.Get:
+		return precCall
+	case *expr.Grouping:
+		return precedence(e.Expression)
+	}
+
+	return precPrimary
+}
+
+// endsOpen returns true if the last thing printed for e, without
+// parentheses, is the trailing expression of a ternary. That expression
+// is parsed as greedily as possible and would swallow a following comma.
+func endsOpen(e expr.Expr) bool {
+	switch e := e.(type) {
+	case *expr.Ternary:
+		return true
+	case *expr.Assign:
+		return endsOpen(e.Value)
+	case *expr.Set:
+		return endsOpen(e.Value)
+	case *expr.Sequenced:
+		return endsOpen(e.Right)
+	case *expr.Grouping:
+		return endsOpen(e.Expression)
+	}
+
+	return false
+}
+
+// literalSource returns the Lox source for the literal value, a float is
+// always written with a fraction or exponent so it isn't scanned as an
+// integer.
+func literalSource(l *expr.Literal) string {
+	switch v := l.Value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+
+		return s
+	case string:
+		return `"` + escapeString(v) + `"`
+	}
+
+	return "nil"
+}
+
+// escapeString escapes the characters of s that can't appear as themselves
+// inside a Lox string.
+func escapeString(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '$' && strings.HasPrefix(s[i+1:], "{"):
+			sb.WriteString(`\$`)
+		case !unicode.IsPrint(r):
+			sb.WriteString(`\u{` + strconv.FormatInt(int64(r), 16) + `}`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
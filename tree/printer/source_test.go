@@ -0,0 +1,259 @@
+package printer_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/bbuck/glox/errs"
+	"github.com/bbuck/glox/scanner"
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/expr"
+	"github.com/bbuck/glox/tree/parser"
+	"github.com/bbuck/glox/tree/printer"
+	"github.com/bbuck/glox/tree/stmt"
+)
+
+func Test_PrintSource(t *testing.T) {
+	a, b, c, d := variable("a"), variable("b"), variable("c"), variable("d")
+	tests := map[string]expr.Expr{
+		"(8.0 + 10.0) * -8.0":                      ex,
+		"88.0 - 44.0 ? -1.0 : (8.0 + 10.0) * -8.0": tex,
+		`"sum: ${(8.0 + 10.0) * -8.0}\n"`:          iex,
+		"a - (b - c)":                              binary(a, minus, binary(b, minus, c)),
+		"a - b - c":                                binary(binary(a, minus, b), minus, c),
+		"(a ? b : c) ? d : a":                      expr.NewTernary(expr.NewTernary(a, b, c), d, a),
+		"a ? b : c ? d : a":                        expr.NewTernary(a, b, expr.NewTernary(c, d, a)),
+		"(a ? b : c), d":                           expr.NewSequenced(expr.NewTernary(a, b, c), d),
+		"a, (b, c)":                                expr.NewSequenced(a, expr.NewSequenced(b, c)),
+		"a = b = c":                                expr.NewAssign(a.Name, expr.NewAssign(b.Name, c)),
+		"f((a = b ? c : d), a, (b, c))": expr.NewCall(variable("f"), nil, []expr.Expr{
+			expr.NewAssign(a.Name, expr.NewTernary(b, c, d)),
+			a,
+			expr.NewSequenced(b, c),
+		}),
+		"(a + b).c = -d": expr.NewSet(binary(a, plus, b), c.Name, expr.NewUnary(minus, d)),
+		"2.0 + 2":        binary(number(2), plus, expr.NewLiteral(expr.IntegerLiteral, int64(2))),
+		`"\${\"\\"`:      str(`${"\`),
+		"fun (a) { (fun () { }); return a; }": expr.NewLambda(nil, []*token.T{a.Name}, []interface{}{
+			stmt.NewExpression(expr.NewLambda(nil, nil, nil, nil)),
+			stmt.NewReturn(nil, a),
+		}, nil),
+	}
+
+	for expected, e := range tests {
+		expect(t, expected, printer.PrintSource(e))
+	}
+}
+
+// Test_PrintSource_RoundTrip checks that random trees print as source that
+// parses back to the same tree.
+func Test_PrintSource_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		e := randomExpr(r, 5)
+		source := printer.PrintSource(e)
+
+		reporter := errs.NewCollector()
+		s := scanner.New(source, reporter)
+		s.ScanTokens()
+		parsed := parser.New(s.Tokens(), reporter).Parse()
+		if parsed == nil || len(reporter.Diagnostics) > 0 {
+			t.Errorf("%q: failed to parse: %v", source, reporter.Diagnostics)
+			continue
+		}
+
+		if expected, result := structure(e), structure(parsed); expected != result {
+			t.Errorf("%q: expected %s but parsed %s", source, expected, result)
+		}
+	}
+}
+
+var (
+	names     = []string{"a", "b", "foo", "funny"}
+	operators = []*token.T{
+		token.New(token.BangEqual, "!=", nil, 1),
+		token.New(token.EqualEqual, "==", nil, 1),
+		token.New(token.Greater, ">", nil, 1),
+		token.New(token.GreaterEqual, ">=", nil, 1),
+		token.New(token.Less, "<", nil, 1),
+		token.New(token.LessEqual, "<=", nil, 1),
+		minus,
+		plus,
+		token.New(token.Slash, "/", nil, 1),
+		star,
+		token.New(token.Percent, "%", nil, 1),
+	}
+	logical = []*token.T{
+		token.New(token.And, "and", nil, 1),
+		token.New(token.Or, "or", nil, 1),
+	}
+	unary = []*token.T{
+		minus,
+		token.New(token.Bang, "!", nil, 1),
+	}
+	literals = []*expr.Literal{
+		number(0),
+		number(2),
+		number(0.1),
+		number(1e300),
+		number(1e-7),
+		expr.NewLiteral(expr.IntegerLiteral, int64(0)),
+		expr.NewLiteral(expr.IntegerLiteral, int64(9223372036854775807)),
+		str(""),
+		str("a \"quoted\" \\ string\n\t\r"),
+		str("$ ${x} $${ $"),
+		str("café ​"),
+		expr.NewLiteral(expr.BooleanLiteral, true),
+		expr.NewLiteral(expr.BooleanLiteral, false),
+		expr.NewLiteral(expr.NilLiteral, nil),
+	}
+)
+
+func randomExpr(r *rand.Rand, depth int) expr.Expr {
+	if depth == 0 || r.Intn(4) == 0 {
+		switch r.Intn(4) {
+		case 0:
+			return variable(names[r.Intn(len(names))])
+		case 1:
+			return expr.NewThis(token.New(token.This, "this", nil, 1))
+		case 2:
+			return expr.NewSuper(token.New(token.Super, "super", nil, 1), name(r))
+		}
+
+		return literals[r.Intn(len(literals))]
+	}
+
+	depth--
+	switch r.Intn(13) {
+	case 0:
+		return binary(randomExpr(r, depth), operators[r.Intn(len(operators))], randomExpr(r, depth))
+	case 1:
+		return expr.NewLogical(randomExpr(r, depth), logical[r.Intn(len(logical))], randomExpr(r, depth))
+	case 2:
+		return expr.NewUnary(unary[r.Intn(len(unary))], randomExpr(r, depth))
+	case 3:
+		return expr.NewGrouping(nil, randomExpr(r, depth), nil)
+	case 4:
+		return expr.NewSequenced(randomExpr(r, depth), randomExpr(r, depth))
+	case 5:
+		return expr.NewTernary(randomExpr(r, depth), randomExpr(r, depth), randomExpr(r, depth))
+	case 6:
+		return expr.NewAssign(name(r), randomExpr(r, depth))
+	case 7:
+		return expr.NewSet(randomExpr(r, depth), name(r), randomExpr(r, depth))
+	case 8:
+		return expr.NewGet(randomExpr(r, depth), name(r))
+	case 9:
+		args := make([]expr.Expr, r.Intn(4))
+		for i := range args {
+			args[i] = randomExpr(r, depth)
+		}
+
+		return expr.NewCall(randomExpr(r, depth), nil, args)
+	case 10:
+		parts := []expr.Expr{literals[8]}
+		for i := r.Intn(2); i >= 0; i-- {
+			parts = append(parts, randomExpr(r, depth), literals[9])
+		}
+
+		return expr.NewInterpolation(parts)
+	case 11:
+		params := make([]*token.T, r.Intn(3))
+		for i := range params {
+			params[i] = name(r)
+		}
+
+		return expr.NewLambda(nil, params, []interface{}{
+			stmt.NewExpression(randomExpr(r, depth)),
+			stmt.NewPrint(randomExpr(r, depth)),
+			stmt.NewVar(name(r), randomExpr(r, depth)),
+			stmt.NewReturn(nil, randomExpr(r, depth)),
+		}, nil)
+	}
+
+	return literals[r.Intn(len(literals))]
+}
+
+// structure describes the shape of a tree, ignoring groupings and where
+// the tokens came from.
+func structure(node interface{}) string {
+	switch n := node.(type) {
+	case *expr.Binary:
+		return fmt.Sprintf("(binary %s %s %s)", n.Operator.Lexeme, structure(n.Left), structure(n.Right))
+	case *expr.Logical:
+		return fmt.Sprintf("(logical %s %s %s)", n.Operator.Lexeme, structure(n.Left), structure(n.Right))
+	case *expr.Unary:
+		return fmt.Sprintf("(unary %s %s)", n.Operator.Lexeme, structure(n.Right))
+	case *expr.Grouping:
+		return structure(n.Expression)
+	case *expr.Literal:
+		return fmt.Sprintf("(literal %d %T %#v)", n.Type, n.Value, n.Value)
+	case *expr.Sequenced:
+		return fmt.Sprintf("(sequenced %s %s)", structure(n.Left), structure(n.Right))
+	case *expr.Ternary:
+		return fmt.Sprintf("(ternary %s %s %s)", structure(n.Condition), structure(n.Positive), structure(n.Negative))
+	case *expr.Variable:
+		return n.Name.Lexeme
+	case *expr.Assign:
+		return fmt.Sprintf("(assign %s %s)", n.Name.Lexeme, structure(n.Value))
+	case *expr.Set:
+		return fmt.Sprintf("(set %s %s %s)", structure(n.Object), n.Name.Lexeme, structure(n.Value))
+	case *expr.Get:
+		return fmt.Sprintf("(get %s %s)", structure(n.Object), n.Name.Lexeme)
+	case *expr.Call:
+		return fmt.Sprintf("(call %s%s)", structure(n.Callee), structures(n.Arguments))
+	case *expr.This:
+		return "this"
+	case *expr.Super:
+		return "(super " + n.Method.Lexeme + ")"
+	case *expr.Interpolation:
+		return fmt.Sprintf("(interpolate%s)", structures(n.Parts))
+	case *expr.Lambda:
+		params := make([]string, len(n.Params))
+		for i, param := range n.Params {
+			params[i] = param.Lexeme
+		}
+
+		return fmt.Sprintf("(fun (%s)%s)", strings.Join(params, " "), structures(n.Body))
+	case *stmt.Expression:
+		return fmt.Sprintf("(expression %s)", structure(n.Expression))
+	case *stmt.Print:
+		return fmt.Sprintf("(print %s)", structure(n.Expression))
+	case *stmt.Var:
+		return fmt.Sprintf("(var %s %s)", n.Name.Lexeme, structure(n.Initializer))
+	case *stmt.Return:
+		return fmt.Sprintf("(return %s)", structure(n.Value))
+	}
+
+	return fmt.Sprintf("(unknown %T)", node)
+}
+
+func structures(nodes interface{}) string {
+	var sb strings.Builder
+	switch ns := nodes.(type) {
+	case []expr.Expr:
+		for _, n := range ns {
+			sb.WriteString(" " + structure(n))
+		}
+	case []interface{}:
+		for _, n := range ns {
+			sb.WriteString(" " + structure(n))
+		}
+	}
+
+	return sb.String()
+}
+
+func variable(n string) *expr.Variable {
+	return expr.NewVariable(token.New(token.Identifier, n, nil, 1))
+}
+
+func name(r *rand.Rand) *token.T {
+	return token.New(token.Identifier, names[r.Intn(len(names))], nil, 1)
+}
+
+func binary(left expr.Expr, op *token.T, right expr.Expr) *expr.Binary {
+	return expr.NewBinary(left, op, right)
+}
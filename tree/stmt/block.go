@@ -0,0 +1,19 @@
+package stmt
+
+// Block represents a list of statements wrapped in braces, the statements
+// are executed in their own nested scope.
+type Block struct {
+	Statements []Stmt
+}
+
+// NewBlock constructs and returns a new Block statement.
+func NewBlock(stmts []Stmt) *Block {
+	return &Block{
+		Statements: stmts,
+	}
+}
+
+// Accept for Block calls the VisitBlock method on the visitor.
+func (b *Block) Accept(v Visitor) {
+	v.VisitBlock(b)
+}
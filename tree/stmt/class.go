@@ -0,0 +1,28 @@
+package stmt
+
+import (
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/expr"
+)
+
+// Class represents a class declaration with its methods. Superclass will be
+// nil when the class does not inherit from another class.
+type Class struct {
+	Name       *token.T
+	Superclass *expr.Variable
+	Methods    []*Function
+}
+
+// NewClass constructs and returns a new Class statement.
+func NewClass(name *token.T, superclass *expr.Variable, methods []*Function) *Class {
+	return &Class{
+		Name:       name,
+		Superclass: superclass,
+		Methods:    methods,
+	}
+}
+
+// Accept for Class calls the VisitClass method on the visitor.
+func (c *Class) Accept(v Visitor) {
+	v.VisitClass(c)
+}
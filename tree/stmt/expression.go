@@ -0,0 +1,21 @@
+package stmt
+
+import "github.com/bbuck/glox/tree/expr"
+
+// Expression represents a statement consisting of a single expression
+// that is evaluated for its side effects, the result is discarded.
+type Expression struct {
+	Expression expr.Expr
+}
+
+// NewExpression constructs and returns a new Expression statement.
+func NewExpression(ex expr.Expr) *Expression {
+	return &Expression{
+		Expression: ex,
+	}
+}
+
+// Accept for Expression calls the VisitExpression method on the visitor.
+func (e *Expression) Accept(v Visitor) {
+	v.VisitExpression(e)
+}
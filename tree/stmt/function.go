@@ -0,0 +1,25 @@
+package stmt
+
+import "github.com/bbuck/glox/token"
+
+// Function represents a named function declaration, the parameter names
+// and the statements that make up the body of the function.
+type Function struct {
+	Name   *token.T
+	Params []*token.T
+	Body   []Stmt
+}
+
+// NewFunction constructs and returns a new Function statement.
+func NewFunction(name *token.T, params []*token.T, body []Stmt) *Function {
+	return &Function{
+		Name:   name,
+		Params: params,
+		Body:   body,
+	}
+}
+
+// Accept for Function calls the VisitFunction method on the visitor.
+func (f *Function) Accept(v Visitor) {
+	v.VisitFunction(f)
+}
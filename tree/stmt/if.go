@@ -0,0 +1,26 @@
+package stmt
+
+import "github.com/bbuck/glox/tree/expr"
+
+// If represents a conditional statement. When the condition is true-thy
+// the Then branch is executed, otherwise the Else branch is executed if
+// one was given. Else will be nil when there is no else branch.
+type If struct {
+	Condition expr.Expr
+	Then      Stmt
+	Else      Stmt
+}
+
+// NewIf constructs and returns a new If statement.
+func NewIf(cond expr.Expr, then, els Stmt) *If {
+	return &If{
+		Condition: cond,
+		Then:      then,
+		Else:      els,
+	}
+}
+
+// Accept for If calls the VisitIf method on the visitor.
+func (i *If) Accept(v Visitor) {
+	v.VisitIf(i)
+}
@@ -0,0 +1,21 @@
+package stmt
+
+import "github.com/bbuck/glox/tree/expr"
+
+// Print represents a statement that evaluates an expression and writes
+// the resulting value out for the user to see.
+type Print struct {
+	Expression expr.Expr
+}
+
+// NewPrint constructs and returns a new Print statement.
+func NewPrint(ex expr.Expr) *Print {
+	return &Print{
+		Expression: ex,
+	}
+}
+
+// Accept for Print calls the VisitPrint method on the visitor.
+func (p *Print) Accept(v Visitor) {
+	v.VisitPrint(p)
+}
@@ -0,0 +1,27 @@
+package stmt
+
+import (
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/expr"
+)
+
+// Return represents returning from the current function call. The Value
+// will be nil for a bare `return;` and the Keyword is kept for reporting
+// errors.
+type Return struct {
+	Keyword *token.T
+	Value   expr.Expr
+}
+
+// NewReturn constructs and returns a new Return statement.
+func NewReturn(keyword *token.T, value expr.Expr) *Return {
+	return &Return{
+		Keyword: keyword,
+		Value:   value,
+	}
+}
+
+// Accept for Return calls the VisitReturn method on the visitor.
+func (r *Return) Accept(v Visitor) {
+	v.VisitReturn(r)
+}
@@ -0,0 +1,6 @@
+package stmt
+
+// Stmt is a statement interface that defines an Accept method
+type Stmt interface {
+	Accept(Visitor)
+}
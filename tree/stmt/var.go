@@ -0,0 +1,26 @@
+package stmt
+
+import (
+	"github.com/bbuck/glox/token"
+	"github.com/bbuck/glox/tree/expr"
+)
+
+// Var represents the declaration of a new variable with an optional
+// initial value. If no Initializer is given the variable starts as nil.
+type Var struct {
+	Name        *token.T
+	Initializer expr.Expr
+}
+
+// NewVar constructs and returns a new Var statement.
+func NewVar(name *token.T, init expr.Expr) *Var {
+	return &Var{
+		Name:        name,
+		Initializer: init,
+	}
+}
+
+// Accept for Var calls the VisitVar method on the visitor.
+func (vr *Var) Accept(v Visitor) {
+	v.VisitVar(vr)
+}
@@ -0,0 +1,15 @@
+package stmt
+
+// Visitor defines a statement visitor interface, implement this and
+// you can pass it into a statements Accept method.
+type Visitor interface {
+	VisitExpression(*Expression)
+	VisitPrint(*Print)
+	VisitVar(*Var)
+	VisitBlock(*Block)
+	VisitIf(*If)
+	VisitWhile(*While)
+	VisitFunction(*Function)
+	VisitReturn(*Return)
+	VisitClass(*Class)
+}
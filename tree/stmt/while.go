@@ -0,0 +1,24 @@
+package stmt
+
+import "github.com/bbuck/glox/tree/expr"
+
+// While represents a loop that executes the Body for as long as the
+// Condition remains true-thy. For loops are desugared into While loops
+// by the parser.
+type While struct {
+	Condition expr.Expr
+	Body      Stmt
+}
+
+// NewWhile constructs and returns a new While statement.
+func NewWhile(cond expr.Expr, body Stmt) *While {
+	return &While{
+		Condition: cond,
+		Body:      body,
+	}
+}
+
+// Accept for While calls the VisitWhile method on the visitor.
+func (w *While) Accept(v Visitor) {
+	v.VisitWhile(w)
+}